@@ -0,0 +1,117 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"github.com/pivotal-cloudops/bosh-stats/deployments"
+)
+
+func runChurn(args []string) error {
+	flags := newFlagSet("churn", "-calendarMonth YYYY/MM [options]", "Count VM, instance and disk creates, deletes, recreates, restarts, stops and starts\nper deployment and instance group in the reporting period.")
+	connection := addConnectionFlags(flags, true)
+	periodOpts := addPeriodFlags(flags)
+	deployment := flags.String("deployment", "", "The deployment to filter out")
+	outputJson := flags.Bool("json", false, "print JSON to standard out (output is a table by default)")
+	formatOpts := addFormatFlags(flags)
+	flags.Parse(args)
+
+	location, reportingPeriod := mustParsePeriod(flags, periodOpts)
+	if err := formatOpts.validate(*outputJson); err != nil {
+		exitWithUsage(flags, err)
+	}
+	if err := connection.validate(); err != nil {
+		exitWithUsage(flags, err)
+	}
+	deployCounter := connection.deployCounter(location)
+
+	churn := make(map[string]map[string]map[string]int)
+	err := deployCounter.Churn(periodOpts.spec(), itemsPerPage, &churn, *deployment)
+	if err != nil {
+		return err
+	}
+
+	if formatOpts.delimited() {
+		formatOpts.print(churnTable(churn))
+	} else if *outputJson {
+		printJSON(churn)
+	} else {
+		printChurn(churn, reportingPeriod.Label())
+	}
+	return nil
+}
+
+func printChurn(churn map[string]map[string]map[string]int, periodLabel string) {
+	totalByAction := make(map[string]int)
+	totalChurn := 0
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 1, ' ', tabwriter.AlignRight|tabwriter.Debug)
+
+	header := []interface{}{"Deployment", "\t", "Instance group"}
+	separator := []interface{}{"--------------------", "\t", "--------------------"}
+	for _, action := range deployments.ChurnActions {
+		header = append(header, "\t", action)
+		separator = append(separator, "\t", "----------")
+	}
+	header = append(header, "\t", "Total")
+	separator = append(separator, "\t", "----------")
+
+	fmt.Fprintln(w, header...)
+	fmt.Fprintln(w, separator...)
+
+	deploymentNames := sortedKeys(churn)
+
+	for _, deployment := range deploymentNames {
+		for _, instanceGroup := range sortedKeys(churn[deployment]) {
+			instanceGroupChurn := 0
+			row := []interface{}{deployment, "\t", instanceGroup}
+			for _, action := range deployments.ChurnActions {
+				count := churn[deployment][instanceGroup][action]
+				instanceGroupChurn += count
+				totalByAction[action] += count
+				row = append(row, "\t", count)
+			}
+			totalChurn += instanceGroupChurn
+			fmt.Fprintln(w, append(row, "\t", instanceGroupChurn)...)
+		}
+	}
+
+	fmt.Println()
+	fmt.Fprintln(w, separator...)
+	totals := []interface{}{periodLabel, "\t", ""}
+	for _, action := range deployments.ChurnActions {
+		totals = append(totals, "\t", totalByAction[action])
+	}
+	fmt.Fprintln(w, append(totals, "\t", totalChurn)...)
+	w.Flush()
+}
+
+func churnTable(churn map[string]map[string]map[string]int) *table {
+	totalByAction := make(map[string]int)
+	totalChurn := 0
+	t := newTable(append(append([]string{"deployment", "instance_group"}, deployments.ChurnActions...), "total")...)
+
+	deploymentNames := sortedKeys(churn)
+
+	for _, deployment := range deploymentNames {
+		for _, instanceGroup := range sortedKeys(churn[deployment]) {
+			instanceGroupChurn := 0
+			row := []interface{}{deployment, instanceGroup}
+			for _, action := range deployments.ChurnActions {
+				count := churn[deployment][instanceGroup][action]
+				instanceGroupChurn += count
+				totalByAction[action] += count
+				row = append(row, count)
+			}
+			totalChurn += instanceGroupChurn
+			t.addRow(append(row, instanceGroupChurn)...)
+		}
+	}
+
+	totals := []interface{}{"total", ""}
+	for _, action := range deployments.ChurnActions {
+		totals = append(totals, totalByAction[action])
+	}
+	t.addTotals(append(totals, totalChurn)...)
+	return t
+}
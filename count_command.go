@@ -0,0 +1,460 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/pivotal-cloudops/bosh-stats/deployments"
+	"github.com/pivotal-cloudops/bosh-stats/report"
+)
+
+func runCount(args []string) error {
+	flags := newFlagSet("count", "-calendarMonth YYYY/MM [options]", "Count successful deploys per deployment in the reporting period.")
+	connection := addConnectionFlags(flags, true)
+	periodOpts := addPeriodFlags(flags)
+	repaveUser := addRepaveUserFlag(flags)
+	deployment := flags.String("deployment", "", "The deployment to filter out")
+	failures := flags.Bool("failures", false, "Also count failed deploys and show the failure ratio per deployment")
+	byUser := flags.Bool("byUser", false, "Break the counts down by the user who deployed")
+	changes := flags.Bool("changes", false, "Break the counts down by what the deploys changed: releases, stemcells, both or only config")
+	userClassesFile := flags.String("userClasses", "", "JSON file of user classes, e.g. repave and ci, to break the counts down by; other users count as human")
+	targetsFile := flags.String("targets", "", "JSON file listing several directors to collect deploy counts from instead of -directorUrl")
+	outputJson := flags.Bool("json", false, "print JSON to standard out (output is a table by default)")
+	formatOpts := addFormatFlags(flags)
+	templateName := flags.String("template", "", "Render the counts with this text/template `file`, or a bundled template: builtin:table or builtin:json (see README)")
+	flags.Parse(args)
+
+	location, reportingPeriod := mustParsePeriod(flags, periodOpts)
+	if err := formatOpts.validate(*outputJson); err != nil {
+		exitWithUsage(flags, err)
+	}
+
+	breakdowns := 0
+	for _, breakdown := range []bool{*failures, *byUser, *changes, *userClassesFile != "", *targetsFile != ""} {
+		if breakdown {
+			breakdowns += 1
+		}
+	}
+	if breakdowns > 1 {
+		exitWithUsage(flags, fmt.Errorf("only one of -failures, -byUser, -changes, -userClasses and -targets can be given"))
+	}
+	if *templateName != "" && (breakdowns > 0 || *outputJson || formatOpts.delimited()) {
+		exitWithUsage(flags, fmt.Errorf("-template cannot be combined with -json, -format or a breakdown of the counts"))
+	}
+
+	if *targetsFile != "" {
+		return countFleet(*targetsFile, *connection.cacheDir, location, periodOpts.spec(), reportingPeriod.Label(), *repaveUser, *deployment, *outputJson, formatOpts)
+	}
+
+	if err := connection.validate(); err != nil {
+		exitWithUsage(flags, err)
+	}
+	deployCounter := connection.deployCounter(location)
+
+	if *byUser {
+		return countByUser(deployCounter, periodOpts.spec(), reportingPeriod.Label(), *repaveUser, *deployment, *outputJson, formatOpts)
+	}
+
+	if *changes {
+		return countByChange(deployCounter, periodOpts.spec(), reportingPeriod.Label(), *repaveUser, *deployment, *outputJson, formatOpts)
+	}
+
+	if *userClassesFile != "" {
+		return countByUserClass(deployCounter, *userClassesFile, periodOpts.spec(), reportingPeriod.Label(), *repaveUser, *deployment, *outputJson, formatOpts)
+	}
+
+	if !*failures {
+		countByDeploymentAndUser := make(map[string]map[string]int)
+		err := deployCounter.SuccessfulDeploysByUser(periodOpts.spec(), itemsPerPage, *repaveUser, &countByDeploymentAndUser, *deployment)
+		if err != nil {
+			return err
+		}
+
+		data := newTemplateData(*connection.directorURL, report.TemplatePeriod{
+			Label: reportingPeriod.Label(),
+			Start: reportingPeriod.Start,
+			End:   reportingPeriod.End,
+		}, countByDeploymentAndUser)
+
+		if formatOpts.delimited() {
+			formatOpts.print(countsTable(data.Counts()))
+			return nil
+		}
+
+		if *templateName == "" {
+			*templateName = "builtin:table"
+			if *outputJson {
+				*templateName = "builtin:json"
+			}
+		}
+		countTemplate, err := report.LoadTemplate(*templateName)
+		if err != nil {
+			return err
+		}
+		return countTemplate.Execute(os.Stdout, data)
+	}
+
+	outcomes, err := deployCounter.DeployOutcomes(periodOpts.spec(), itemsPerPage, *repaveUser, *deployment)
+	if err != nil {
+		return err
+	}
+
+	if formatOpts.delimited() {
+		formatOpts.print(outcomesTable(outcomes))
+	} else if *outputJson {
+		printJSON(outcomes)
+	} else {
+		printOutcomes(outcomes, reportingPeriod.Label())
+	}
+	return nil
+}
+
+func countByUser(deployCounter deployments.DeployCounter, periodSpec string, periodLabel string, repaveUser string, deployment string, outputJson bool, formatOpts *formatFlags) error {
+	countByDeploymentAndUser := make(map[string]map[string]int)
+	err := deployCounter.SuccessfulDeploysByUser(periodSpec, itemsPerPage, repaveUser, &countByDeploymentAndUser, deployment)
+	if err != nil {
+		return err
+	}
+
+	if formatOpts.delimited() {
+		formatOpts.print(byUserTable(countByDeploymentAndUser))
+	} else if outputJson {
+		printJSON(countByDeploymentAndUser)
+	} else {
+		printByUser(countByDeploymentAndUser, periodLabel)
+	}
+	return nil
+}
+
+func countByUserClass(deployCounter deployments.DeployCounter, userClassesFile string, periodSpec string, periodLabel string, repaveUser string, deployment string, outputJson bool, formatOpts *formatFlags) error {
+	classes, err := deployments.LoadUserClasses(userClassesFile)
+	if err != nil {
+		return err
+	}
+
+	countByDeploymentAndUser := make(map[string]map[string]int)
+	err = deployCounter.SuccessfulDeploysByUser(periodSpec, itemsPerPage, repaveUser, &countByDeploymentAndUser, deployment)
+	if err != nil {
+		return err
+	}
+
+	countByClass := deployments.CountByUserClass(countByDeploymentAndUser, classes)
+	if formatOpts.delimited() {
+		formatOpts.print(countsByColumnTable(countByClass, deployments.UserClassNames(classes)))
+	} else if outputJson {
+		printJSON(countByClass)
+	} else {
+		printCountsByColumn(countByClass, deployments.UserClassNames(classes), periodLabel)
+	}
+	return nil
+}
+
+func countByChange(deployCounter deployments.DeployCounter, periodSpec string, periodLabel string, repaveUser string, deployment string, outputJson bool, formatOpts *formatFlags) error {
+	countByDeploymentAndChange := make(map[string]map[string]int)
+	err := deployCounter.DeployChanges(periodSpec, itemsPerPage, repaveUser, &countByDeploymentAndChange, deployment)
+	if err != nil {
+		return err
+	}
+
+	if formatOpts.delimited() {
+		formatOpts.print(countsByColumnTable(countByDeploymentAndChange, deployments.DeployChangeClasses))
+	} else if outputJson {
+		printJSON(countByDeploymentAndChange)
+	} else {
+		printCountsByColumn(countByDeploymentAndChange, deployments.DeployChangeClasses, periodLabel)
+	}
+	return nil
+}
+
+func countFleet(targetsFile string, cacheDir string, location *time.Location, periodSpec string, periodLabel string, repaveUser string, deployment string, outputJson bool, formatOpts *formatFlags) error {
+	targets, err := deployments.LoadDirectorTargets(targetsFile)
+	if err != nil {
+		return err
+	}
+
+	for i := range targets {
+		targets[i].Timezone = location
+		if targets[i].CacheDir == "" {
+			targets[i].CacheDir = cacheDir
+		}
+	}
+
+	results := deployments.FleetSuccessfulDeploys(targets, periodSpec, itemsPerPage, repaveUser, deployment)
+	if formatOpts.delimited() {
+		formatOpts.print(fleetTable(results))
+	} else if outputJson {
+		printFleetJSON(results)
+	} else {
+		printFleetResults(results, periodLabel)
+	}
+	return nil
+}
+
+func newTemplateData(director string, period report.TemplatePeriod, countByDeploymentAndUser map[string]map[string]int) report.TemplateData {
+	data := report.TemplateData{Director: director, Period: period}
+	totalByUser := make(map[string]int)
+
+	for _, deployment := range sortedKeys(countByDeploymentAndUser) {
+		deploymentCount := report.DeploymentCount{Name: deployment}
+		for _, user := range sortedKeys(countByDeploymentAndUser[deployment]) {
+			count := countByDeploymentAndUser[deployment][user]
+			deploymentCount.Count += count
+			deploymentCount.Users = append(deploymentCount.Users, report.UserCount{Name: user, Count: count})
+			totalByUser[user] += count
+		}
+
+		data.Total += deploymentCount.Count
+		data.Deployments = append(data.Deployments, deploymentCount)
+	}
+
+	for _, user := range sortedKeys(totalByUser) {
+		data.Users = append(data.Users, report.UserCount{Name: user, Count: totalByUser[user]})
+	}
+	return data
+}
+
+type directorDeploysJSON struct {
+	Director string         `json:"director"`
+	Deploys  map[string]int `json:"deploys,omitempty"`
+	Error    string         `json:"error,omitempty"`
+}
+
+func printFleetJSON(results []deployments.DirectorDeploys) {
+	totalDeploys := 0
+	directors := []directorDeploysJSON{}
+
+	for _, result := range results {
+		directorDeploys := directorDeploysJSON{Director: result.Director, Deploys: result.Deploys}
+		if result.Err != nil {
+			directorDeploys.Error = result.Err.Error()
+		}
+		directors = append(directors, directorDeploys)
+
+		for _, count := range result.Deploys {
+			totalDeploys += count
+		}
+	}
+
+	printJSON(map[string]interface{}{
+		"directors": directors,
+		"total":     totalDeploys,
+	})
+}
+
+func printFleetResults(results []deployments.DirectorDeploys, periodLabel string) {
+	totalDeploys := 0
+	failedDirectors := 0
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 1, ' ', tabwriter.AlignRight|tabwriter.Debug)
+
+	fmt.Fprintln(w, "Director", "\t", "Deployment", "\t", "Count")
+	fmt.Fprintln(w, "--------------------", "\t", "--------------------", "\t", "--------------------")
+
+	for _, result := range results {
+		if result.Err != nil {
+			failedDirectors += 1
+			fmt.Fprintln(w, result.Director, "\t", "ERROR", "\t", result.Err)
+			continue
+		}
+
+		deploymentNames := sortedKeys(result.Deploys)
+
+		for _, deployment := range deploymentNames {
+			totalDeploys += result.Deploys[deployment]
+			fmt.Fprintln(w, result.Director, "\t", deployment, "\t", result.Deploys[deployment], "deploys")
+		}
+	}
+
+	fmt.Println()
+	fmt.Fprintln(w, "--------------------", "\t", "--------------------", "\t", "--------------------")
+	fmt.Fprintln(w, periodLabel, "\t", fmt.Sprintf("%d directors", len(results)-failedDirectors), "\t", totalDeploys, "total deploys")
+	w.Flush()
+}
+
+func printOutcomes(outcomes map[string]deployments.DeployOutcome, periodLabel string) {
+	totalSuccessful := 0
+	totalFailed := 0
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 1, ' ', tabwriter.AlignRight|tabwriter.Debug)
+
+	fmt.Fprintln(w, "Deployment", "\t", "Successful", "\t", "Failed", "\t", "Failure ratio")
+	fmt.Fprintln(w, "--------------------", "\t", "----------", "\t", "----------", "\t", "-------------")
+
+	deploymentNames := sortedKeys(outcomes)
+
+	for _, deployment := range deploymentNames {
+		outcome := outcomes[deployment]
+		totalSuccessful += outcome.Successful
+		totalFailed += outcome.Failed
+		fmt.Fprintln(w, deployment, "\t", outcome.Successful, "\t", outcome.Failed, "\t", formatRatio(outcome.FailureRatio))
+	}
+
+	fmt.Println()
+	fmt.Fprintln(w, "--------------------", "\t", "----------", "\t", "----------", "\t", "-------------")
+	fmt.Fprintln(w, periodLabel, "\t", totalSuccessful, "\t", totalFailed, "\t", formatRatio(deployments.FailureRatio(totalSuccessful, totalFailed)))
+	w.Flush()
+}
+
+func printByUser(countByDeploymentAndUser map[string]map[string]int, periodLabel string) {
+	totalDeploys := 0
+	totalByUser := make(map[string]int)
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 1, ' ', tabwriter.AlignRight|tabwriter.Debug)
+
+	fmt.Fprintln(w, "Deployment", "\t", "User", "\t", "Count")
+	fmt.Fprintln(w, "--------------------", "\t", "--------------------", "\t", "--------------------")
+
+	for _, deployment := range sortedKeys(countByDeploymentAndUser) {
+		countByUser := countByDeploymentAndUser[deployment]
+		for _, user := range sortedKeys(countByUser) {
+			totalDeploys += countByUser[user]
+			totalByUser[user] += countByUser[user]
+			fmt.Fprintln(w, deployment, "\t", user, "\t", countByUser[user], "deploys")
+		}
+	}
+
+	fmt.Println()
+	fmt.Fprintln(w, "--------------------", "\t", "--------------------", "\t", "--------------------")
+	for _, user := range sortedKeys(totalByUser) {
+		fmt.Fprintln(w, periodLabel, "\t", user, "\t", totalByUser[user], "deploys")
+	}
+	fmt.Fprintln(w, periodLabel, "\t", "all users", "\t", totalDeploys, "total deploys")
+	w.Flush()
+}
+
+func printCountsByColumn(countByColumn map[string]map[string]int, columns []string, periodLabel string) {
+	totalDeploys := 0
+	totalByColumn := make(map[string]int)
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 1, ' ', tabwriter.AlignRight|tabwriter.Debug)
+
+	header := []interface{}{"Deployment"}
+	separator := []interface{}{"--------------------"}
+	for _, column := range columns {
+		header = append(header, "\t", column)
+		separator = append(separator, "\t", "----------")
+	}
+	header = append(header, "\t", "Total")
+	separator = append(separator, "\t", "----------")
+
+	fmt.Fprintln(w, header...)
+	fmt.Fprintln(w, separator...)
+
+	for _, deployment := range sortedKeys(countByColumn) {
+		deploymentTotal := 0
+		row := []interface{}{deployment}
+		for _, column := range columns {
+			count := countByColumn[deployment][column]
+			deploymentTotal += count
+			totalByColumn[column] += count
+			row = append(row, "\t", count)
+		}
+		totalDeploys += deploymentTotal
+		fmt.Fprintln(w, append(row, "\t", deploymentTotal)...)
+	}
+
+	fmt.Println()
+	fmt.Fprintln(w, separator...)
+	totals := []interface{}{periodLabel}
+	for _, column := range columns {
+		totals = append(totals, "\t", totalByColumn[column])
+	}
+	fmt.Fprintln(w, append(totals, "\t", totalDeploys)...)
+	w.Flush()
+}
+
+func countsTable(numberByDeployment map[string]int) *table {
+	totalDeploys := 0
+	t := newTable("deployment", "count")
+
+	for _, deployment := range sortedKeys(numberByDeployment) {
+		totalDeploys += numberByDeployment[deployment]
+		t.addRow(deployment, numberByDeployment[deployment])
+	}
+
+	t.addTotals("total", totalDeploys)
+	return t
+}
+
+func fleetTable(results []deployments.DirectorDeploys) *table {
+	totalDeploys := 0
+	t := newTable("director", "deployment", "count", "error")
+
+	for _, result := range results {
+		if result.Err != nil {
+			t.addRow(result.Director, "", "", result.Err)
+			continue
+		}
+
+		for _, deployment := range sortedKeys(result.Deploys) {
+			totalDeploys += result.Deploys[deployment]
+			t.addRow(result.Director, deployment, result.Deploys[deployment], "")
+		}
+	}
+
+	t.addTotals("total", "", totalDeploys, "")
+	return t
+}
+
+func outcomesTable(outcomes map[string]deployments.DeployOutcome) *table {
+	totalSuccessful := 0
+	totalFailed := 0
+	t := newTable("deployment", "successful", "failed", "failure_ratio")
+
+	deploymentNames := sortedKeys(outcomes)
+
+	for _, deployment := range deploymentNames {
+		outcome := outcomes[deployment]
+		totalSuccessful += outcome.Successful
+		totalFailed += outcome.Failed
+		t.addRow(deployment, outcome.Successful, outcome.Failed, outcome.FailureRatio)
+	}
+
+	t.addTotals("total", totalSuccessful, totalFailed, deployments.FailureRatio(totalSuccessful, totalFailed))
+	return t
+}
+
+func byUserTable(countByDeploymentAndUser map[string]map[string]int) *table {
+	totalDeploys := 0
+	totalByUser := make(map[string]int)
+	t := newTable("deployment", "user", "count")
+
+	for _, deployment := range sortedKeys(countByDeploymentAndUser) {
+		countByUser := countByDeploymentAndUser[deployment]
+		for _, user := range sortedKeys(countByUser) {
+			totalDeploys += countByUser[user]
+			totalByUser[user] += countByUser[user]
+			t.addRow(deployment, user, countByUser[user])
+		}
+	}
+
+	for _, user := range sortedKeys(totalByUser) {
+		t.addTotals("total", user, totalByUser[user])
+	}
+	t.addTotals("total", "", totalDeploys)
+	return t
+}
+
+func countsByColumnTable(countByColumn map[string]map[string]int, columns []string) *table {
+	totalDeploys := 0
+	totalByColumn := make(map[string]int)
+	t := newTable(append(append([]string{"deployment"}, columns...), "total")...)
+
+	for _, deployment := range sortedKeys(countByColumn) {
+		deploymentTotal := 0
+		row := []interface{}{deployment}
+		for _, column := range columns {
+			count := countByColumn[deployment][column]
+			deploymentTotal += count
+			totalByColumn[column] += count
+			row = append(row, count)
+		}
+		totalDeploys += deploymentTotal
+		t.addRow(append(row, deploymentTotal)...)
+	}
+
+	totals := []interface{}{"total"}
+	for _, column := range columns {
+		totals = append(totals, totalByColumn[column])
+	}
+	t.addTotals(append(totals, totalDeploys)...)
+	return t
+}
@@ -0,0 +1,29 @@
+package main
+
+import (
+	"fmt"
+	"time"
+)
+
+func runDeployDate(args []string) error {
+	flags := newFlagSet("deploy-date", "-release NAME -version VERSION [options]", "Show when a release version was first rolled out to any deployment.")
+	connection := addConnectionFlags(flags, true)
+	releaseName := flags.String("release", "", "The release to find the deploy date of")
+	releaseVersion := flags.String("version", "", "The release version to find the deploy date of")
+	flags.Parse(args)
+
+	if *releaseName == "" || *releaseVersion == "" {
+		exitWithUsage(flags, fmt.Errorf("-release and -version are required"))
+	}
+	if err := connection.validate(); err != nil {
+		exitWithUsage(flags, err)
+	}
+	deployCounter := connection.deployCounter(time.UTC)
+
+	date, err := deployCounter.DeployDate(*releaseName, *releaseVersion, itemsPerPage)
+	if err != nil {
+		return err
+	}
+	fmt.Println(date)
+	return nil
+}
@@ -0,0 +1,87 @@
+package deployments
+
+import (
+	"strings"
+
+	boshdir "github.com/cloudfoundry/bosh-cli/director"
+	boshlog "github.com/cloudfoundry/bosh-utils/logger"
+)
+
+// ChurnActions are the VM, instance and disk actions counted as churn, as
+// "<object type> <action>".
+var ChurnActions = []string{
+	"vm create",
+	"vm delete",
+	"instance create",
+	"instance delete",
+	"instance recreate",
+	"instance restart",
+	"instance stop",
+	"instance start",
+	"disk create",
+	"disk delete",
+}
+
+func (d *DeployCounter) Churn(period string, itemsPerPage int, runningChurn *map[string]map[string]map[string]int, deployment string) error {
+	logger := boshlog.NewLogger(boshlog.LevelError)
+
+	reportingPeriod, err := d.reportingPeriod(period)
+	if err != nil {
+		return err
+	}
+	opts := createCalendarOpts(reportingPeriod, deployment)
+
+	eventSource, err := createEventSource(d, logger, itemsPerPage)
+	if err != nil {
+		return err
+	}
+
+	err = reduceDeploymentsToCount(eventSource, []boshdir.Event{}, opts, itemsPerPage, func(events []boshdir.Event) {
+		churnEventCount(events, runningChurn)
+	})
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func churnEventCount(events []boshdir.Event, runningChurn *map[string]map[string]map[string]int) {
+	for _, event := range events {
+		// Every action records a begin event and an end event pointing back
+		// to it; only the begin event is counted.
+		if event.ParentID() != "" {
+			continue
+		}
+
+		action := event.ObjectType() + " " + event.Action()
+		if !isChurnAction(action) {
+			continue
+		}
+
+		deploymentName := event.DeploymentName()
+		instanceGroup := InstanceGroup(event.Instance())
+		if (*runningChurn)[deploymentName] == nil {
+			(*runningChurn)[deploymentName] = make(map[string]map[string]int)
+		}
+		if (*runningChurn)[deploymentName][instanceGroup] == nil {
+			(*runningChurn)[deploymentName][instanceGroup] = make(map[string]int)
+		}
+		(*runningChurn)[deploymentName][instanceGroup][action] += 1
+	}
+}
+
+func isChurnAction(action string) bool {
+	for _, churnAction := range ChurnActions {
+		if action == churnAction {
+			return true
+		}
+	}
+	return false
+}
+
+// InstanceGroup returns the instance group of an event's instance, given as
+// "<instance group>/<id>".
+func InstanceGroup(instance string) string {
+	return strings.SplitN(instance, "/", 2)[0]
+}
@@ -0,0 +1,39 @@
+package deployments_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Churn", func() {
+	events := `
+	[
+		{"id": "9", "parent_id": "8", "action": "recreate", "object_type": "instance", "deployment": "cf", "instance": "diego_cell/1"},
+		{"id": "8", "action": "recreate", "object_type": "instance", "deployment": "cf", "instance": "diego_cell/1"},
+		{"id": "7", "action": "delete", "object_type": "vm", "deployment": "cf", "instance": "diego_cell/1"},
+		{"id": "6", "action": "create", "object_type": "vm", "deployment": "cf", "instance": "diego_cell/1"},
+		{"id": "5", "action": "create", "object_type": "vm", "deployment": "cf", "instance": "diego_cell/2"},
+		{"id": "4", "action": "stop", "object_type": "instance", "deployment": "cf", "instance": "router/0"},
+		{"id": "3", "action": "create", "object_type": "disk", "deployment": "mysql", "instance": "mysql/0"},
+		{"id": "2", "action": "update", "object_type": "deployment", "deployment": "cf", "context": {"before": {}, "after": {}}},
+		{"id": "1", "action": "ssh", "object_type": "instance", "deployment": "cf", "instance": "router/0"}
+	]`
+
+	fake := serveDirector(serveEvents(events, "before_time=1448927999&after_time=1446336000"))
+
+	It("counts VM, instance and disk actions per deployment and instance group", func() {
+		runningChurn := make(map[string]map[string]map[string]int)
+		err := fake.deployCounter.Churn("2015/11", 999, &runningChurn, "")
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(runningChurn).To(Equal(map[string]map[string]map[string]int{
+			"cf": {
+				"diego_cell": {"instance recreate": 1, "vm delete": 1, "vm create": 2},
+				"router":     {"instance stop": 1},
+			},
+			"mysql": {
+				"mysql": {"disk create": 1},
+			},
+		}))
+	})
+})
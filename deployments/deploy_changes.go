@@ -0,0 +1,96 @@
+package deployments
+
+import (
+	boshdir "github.com/cloudfoundry/bosh-cli/director"
+	boshlog "github.com/cloudfoundry/bosh-utils/logger"
+)
+
+const (
+	ReleaseChange            = "release"
+	StemcellChange           = "stemcell"
+	ReleaseAndStemcellChange = "release_and_stemcell"
+	ConfigChange             = "config"
+)
+
+var DeployChangeClasses = []string{ReleaseChange, StemcellChange, ReleaseAndStemcellChange, ConfigChange}
+
+func (d *DeployCounter) DeployChanges(period string, itemsPerPage int, repaveUser string, runningCount *map[string]map[string]int, deployment string) error {
+	if _, err := cachedUserMatcher(repaveUser); err != nil {
+		return err
+	}
+
+	logger := boshlog.NewLogger(boshlog.LevelError)
+
+	reportingPeriod, err := d.reportingPeriod(period)
+	if err != nil {
+		return err
+	}
+	opts := createCalendarOpts(reportingPeriod, deployment)
+
+	eventSource, err := createEventSource(d, logger, itemsPerPage)
+	if err != nil {
+		return err
+	}
+
+	err = reduceDeploymentsToCount(eventSource, []boshdir.Event{}, opts, itemsPerPage, func(events []boshdir.Event) {
+		deploymentEventCountByChange(events, runningCount, repaveUser)
+	})
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func deploymentEventCountByChange(events []boshdir.Event, runningCount *map[string]map[string]int, repaveUser string) {
+	for _, event := range events {
+		if isDeployment(event) && IsNotRepaveUser(event, repaveUser) {
+			deploymentName := event.DeploymentName()
+			if (*runningCount)[deploymentName] == nil {
+				(*runningCount)[deploymentName] = make(map[string]int)
+				for _, class := range DeployChangeClasses {
+					(*runningCount)[deploymentName][class] = 0
+				}
+			}
+			(*runningCount)[deploymentName][ClassifyDeployChange(event)] += 1
+		}
+	}
+}
+
+// ClassifyDeployChange compares the releases and stemcells before and after a
+// deploy. A side whose context does not list them counts as empty, so a
+// create counts its releases and stemcells as changed. Deploys that change
+// neither only changed the manifest or configs.
+func ClassifyDeployChange(event boshdir.Event) string {
+	releasesChanged := contextChanged(event, "releases")
+	stemcellsChanged := contextChanged(event, "stemcells")
+
+	switch {
+	case releasesChanged && stemcellsChanged:
+		return ReleaseAndStemcellChange
+	case releasesChanged:
+		return ReleaseChange
+	case stemcellsChanged:
+		return StemcellChange
+	default:
+		return ConfigChange
+	}
+}
+
+func contextChanged(event boshdir.Event, key string) bool {
+	before, beforeOk := contextEntries(event, "before", key)
+	after, afterOk := contextEntries(event, "after", key)
+	if !beforeOk && !afterOk {
+		return false
+	}
+
+	if len(before) != len(after) {
+		return true
+	}
+	for name, versions := range after {
+		if !sameVersions(before[name], versions) {
+			return true
+		}
+	}
+	return false
+}
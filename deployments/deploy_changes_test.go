@@ -0,0 +1,102 @@
+package deployments_test
+
+import (
+	"github.com/cloudfoundry/bosh-cli/director/directorfakes"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/pivotal-cloudops/bosh-stats/deployments"
+)
+
+var _ = Describe("Deploy changes", func() {
+	Describe("#ClassifyDeployChange", func() {
+		classify := func(before map[string]interface{}, after map[string]interface{}) string {
+			var event = new(directorfakes.FakeEvent)
+			event.ContextReturns(map[string]interface{}{"before": before, "after": after})
+			return deployments.ClassifyDeployChange(event)
+		}
+
+		It("tells release bumps, stemcell bumps, both and config changes apart", func() {
+			Expect(classify(
+				map[string]interface{}{"releases": []interface{}{"cf/122"}, "stemcells": []interface{}{"ubuntu-trusty/3421.9"}},
+				map[string]interface{}{"releases": []interface{}{"cf/123"}, "stemcells": []interface{}{"ubuntu-trusty/3421.9"}},
+			)).To(Equal(deployments.ReleaseChange))
+
+			Expect(classify(
+				map[string]interface{}{"releases": []interface{}{"cf/123"}, "stemcells": []interface{}{"ubuntu-trusty/3421.9"}},
+				map[string]interface{}{"releases": []interface{}{"cf/123"}, "stemcells": []interface{}{"ubuntu-trusty/3421.11"}},
+			)).To(Equal(deployments.StemcellChange))
+
+			Expect(classify(
+				map[string]interface{}{"releases": []interface{}{"cf/122"}, "stemcells": []interface{}{"ubuntu-trusty/3421.9"}},
+				map[string]interface{}{"releases": []interface{}{"cf/123"}, "stemcells": []interface{}{"ubuntu-trusty/3421.11"}},
+			)).To(Equal(deployments.ReleaseAndStemcellChange))
+
+			Expect(classify(
+				map[string]interface{}{"releases": []interface{}{"cf/123", "routing/0.150.0"}, "stemcells": []interface{}{"ubuntu-trusty/3421.11"}},
+				map[string]interface{}{"releases": []interface{}{"routing/0.150.0", "cf/123"}, "stemcells": []interface{}{"ubuntu-trusty/3421.11"}},
+			)).To(Equal(deployments.ConfigChange))
+		})
+
+		It("counts an added release as a release change", func() {
+			Expect(classify(
+				map[string]interface{}{"releases": []interface{}{"cf/123"}},
+				map[string]interface{}{"releases": []interface{}{"cf/123", "routing/0.150.0"}},
+			)).To(Equal(deployments.ReleaseChange))
+		})
+
+		It("counts the releases and stemcells of a create as changed", func() {
+			Expect(classify(
+				map[string]interface{}{},
+				map[string]interface{}{"releases": []interface{}{"cf/123"}, "stemcells": []interface{}{"ubuntu-trusty/3421.11"}},
+			)).To(Equal(deployments.ReleaseAndStemcellChange))
+		})
+
+		It("counts a removed stemcell list as a stemcell change", func() {
+			Expect(classify(
+				map[string]interface{}{"releases": []interface{}{"cf/123"}, "stemcells": []interface{}{"ubuntu-trusty/3421.11"}},
+				map[string]interface{}{"releases": []interface{}{"cf/123"}},
+			)).To(Equal(deployments.StemcellChange))
+		})
+	})
+
+	Describe("#DeployChanges", func() {
+		events := `
+		[
+			{
+				"id": "3",
+				"action": "update",
+				"user": "admin",
+				"object_type": "deployment",
+				"deployment": "cf",
+				"context": {"before": {"releases": ["cf/123"]}, "after": {"releases": ["cf/123"]}}
+			},
+			{
+				"id": "2",
+				"action": "update",
+				"user": "repave",
+				"object_type": "deployment",
+				"deployment": "cf",
+				"context": {"before": {"stemcells": ["ubuntu-trusty/3421.9"]}, "after": {"stemcells": ["ubuntu-trusty/3421.11"]}}
+			},
+			{
+				"id": "1",
+				"action": "update",
+				"user": "admin",
+				"object_type": "deployment",
+				"deployment": "cf",
+				"context": {"before": {"releases": ["cf/122"]}, "after": {"releases": ["cf/123"]}}
+			}
+		]`
+
+		fake := serveDirector(serveEvents(events, "before_time=1448927999&after_time=1446336000"))
+
+		It("counts deploys per change class and deployment", func() {
+			runningCount := make(map[string]map[string]int)
+			err := fake.deployCounter.DeployChanges("2015/11", 999, "repave", &runningCount, "")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(runningCount).To(Equal(map[string]map[string]int{
+				"cf": {"release": 1, "stemcell": 0, "release_and_stemcell": 0, "config": 1},
+			}))
+		})
+	})
+})
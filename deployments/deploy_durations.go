@@ -0,0 +1,152 @@
+package deployments
+
+import (
+	"encoding/json"
+	"math"
+	"sort"
+	"strconv"
+	"time"
+
+	boshdir "github.com/cloudfoundry/bosh-cli/director"
+	boshlog "github.com/cloudfoundry/bosh-utils/logger"
+)
+
+type DurationStats struct {
+	Count  int
+	Min    time.Duration
+	Median time.Duration
+	P95    time.Duration
+	Max    time.Duration
+}
+
+func (d *DeployCounter) DeployDurations(period string, itemsPerPage int, repaveUser string, runningDurations *map[string][]time.Duration, deployment string) error {
+	if _, err := cachedUserMatcher(repaveUser); err != nil {
+		return err
+	}
+
+	logger := boshlog.NewLogger(boshlog.LevelError)
+
+	reportingPeriod, err := d.reportingPeriod(period)
+	if err != nil {
+		return err
+	}
+	opts := createCalendarOpts(reportingPeriod, deployment)
+
+	eventSource, directorClient, err := createEventSourceAndDirector(d, logger, itemsPerPage)
+	if err != nil {
+		return err
+	}
+
+	pendingEndEvents := make(map[string]boshdir.Event)
+	unpairedEndEvents := []boshdir.Event{}
+
+	err = reduceDeploymentsToCount(eventSource, []boshdir.Event{}, opts, itemsPerPage, func(events []boshdir.Event) {
+		unpairedEndEvents = deploymentEventDurations(events, pendingEndEvents, unpairedEndEvents, runningDurations, repaveUser)
+	})
+	if err != nil {
+		return err
+	}
+
+	// Deploys that began before the reporting window have no begin event to
+	// pair with, so ask the director how long their task ran instead. Tasks
+	// are not available when reading events from a file.
+	if directorClient == nil {
+		return nil
+	}
+
+	for _, endEvent := range pendingEndEvents {
+		unpairedEndEvents = append(unpairedEndEvents, endEvent)
+	}
+
+	for _, endEvent := range unpairedEndEvents {
+		duration, found, err := taskDuration(directorClient, endEvent)
+		if err != nil {
+			return err
+		}
+		if found {
+			deploymentName := endEvent.DeploymentName()
+			(*runningDurations)[deploymentName] = append((*runningDurations)[deploymentName], duration)
+		}
+	}
+
+	return nil
+}
+
+func deploymentEventDurations(events []boshdir.Event, pendingEndEvents map[string]boshdir.Event, unpairedEndEvents []boshdir.Event, runningDurations *map[string][]time.Duration, repaveUser string) []boshdir.Event {
+	for _, event := range events {
+		if endEvent, ok := pendingEndEvents[event.ID()]; ok {
+			deploymentName := endEvent.DeploymentName()
+			(*runningDurations)[deploymentName] = append((*runningDurations)[deploymentName], endEvent.Timestamp().Sub(event.Timestamp()))
+			delete(pendingEndEvents, event.ID())
+			continue
+		}
+
+		if isDeployment(event) && IsNotRepaveUser(event, repaveUser) {
+			if event.ParentID() == "" {
+				unpairedEndEvents = append(unpairedEndEvents, event)
+			} else {
+				pendingEndEvents[event.ParentID()] = event
+			}
+		}
+	}
+
+	return unpairedEndEvents
+}
+
+func taskDuration(directorClient boshdir.Director, event boshdir.Event) (time.Duration, bool, error) {
+	taskID, err := strconv.Atoi(event.TaskID())
+	if err != nil {
+		return 0, false, nil
+	}
+
+	task, err := directorClient.FindTask(taskID)
+	if err != nil {
+		return 0, false, err
+	}
+
+	if task.StartedAt().IsZero() || task.LastActivityAt().Before(task.StartedAt()) {
+		return 0, false, nil
+	}
+
+	return task.LastActivityAt().Sub(task.StartedAt()), true, nil
+}
+
+type byDuration []time.Duration
+
+func (d byDuration) Len() int           { return len(d) }
+func (d byDuration) Less(i, j int) bool { return d[i] < d[j] }
+func (d byDuration) Swap(i, j int)      { d[i], d[j] = d[j], d[i] }
+
+func SummarizeDurations(durations []time.Duration) DurationStats {
+	if len(durations) == 0 {
+		return DurationStats{}
+	}
+
+	sorted := make([]time.Duration, len(durations))
+	copy(sorted, durations)
+	sort.Sort(byDuration(sorted))
+
+	count := len(sorted)
+	median := sorted[count/2]
+	if count%2 == 0 {
+		median = (sorted[count/2-1] + sorted[count/2]) / 2
+	}
+
+	return DurationStats{
+		Count:  count,
+		Min:    sorted[0],
+		Median: median,
+		P95:    sorted[int(math.Ceil(0.95*float64(count)))-1],
+		Max:    sorted[count-1],
+	}
+}
+
+func (s DurationStats) MarshalJSON() ([]byte, error) {
+	return json.Marshal(map[string]interface{}{
+		"count":          s.Count,
+		"min_seconds":    s.Min.Seconds(),
+		"median_seconds": s.Median.Seconds(),
+		"p95_seconds":    s.P95.Seconds(),
+		"max_seconds":    s.Max.Seconds(),
+	})
+}
@@ -0,0 +1,100 @@
+package deployments_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/onsi/gomega/ghttp"
+	"github.com/pivotal-cloudops/bosh-stats/deployments"
+)
+
+var _ = Describe("deploy durations", func() {
+	fake := serveDirector()
+
+	It("pairs end events with their begin events and falls back to the task for unpaired ones", func() {
+		events := `
+		[
+			{
+				"id": "5",
+				"parent_id": "4",
+				"action": "update",
+				"timestamp": 1448000600,
+				"user": "admin",
+				"object_type": "deployment",
+				"object_name": "cf",
+				"deployment": "cf",
+				"task": "12",
+				"context": {"before": {}, "after": {}}
+			},
+			{
+				"id": "4",
+				"action": "update",
+				"timestamp": 1448000000,
+				"user": "admin",
+				"object_type": "deployment",
+				"object_name": "cf",
+				"deployment": "cf",
+				"task": "12"
+			},
+			{
+				"id": "3",
+				"parent_id": "1",
+				"action": "update",
+				"timestamp": 1446336100,
+				"user": "admin",
+				"object_type": "deployment",
+				"object_name": "diego",
+				"deployment": "diego",
+				"task": "7",
+				"context": {"before": {}, "after": {}}
+			}
+		]`
+
+		fake.director.AppendHandlers(
+			serveEvents(events, "before_time=1448927999&after_time=1446336000"),
+			ghttp.CombineHandlers(
+				ghttp.VerifyRequest("GET", "/tasks/7"),
+				ghttp.RespondWith(http.StatusOK, `{"id": 7, "state": "done", "started_at": 1446335900, "timestamp": 1446336100}`),
+			),
+		)
+
+		runningDurations := make(map[string][]time.Duration)
+		err := fake.deployCounter.DeployDurations("2015/11", 999, "repave", &runningDurations, "")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(runningDurations).To(Equal(map[string][]time.Duration{
+			"cf":    {10 * time.Minute},
+			"diego": {200 * time.Second},
+		}))
+	})
+})
+
+var _ = Describe("SummarizeDurations", func() {
+	It("returns min, median, p95 and max", func() {
+		durations := []time.Duration{}
+		for i := 20; i > 0; i-- {
+			durations = append(durations, time.Duration(i)*time.Minute)
+		}
+
+		stats := deployments.SummarizeDurations(durations)
+		Expect(stats).To(Equal(deployments.DurationStats{
+			Count:  20,
+			Min:    1 * time.Minute,
+			Median: 10*time.Minute + 30*time.Second,
+			P95:    19 * time.Minute,
+			Max:    20 * time.Minute,
+		}))
+	})
+
+	It("returns zeros when there are no durations", func() {
+		Expect(deployments.SummarizeDurations(nil)).To(Equal(deployments.DurationStats{}))
+	})
+
+	It("marshals durations as seconds", func() {
+		jsonOutput, err := json.Marshal(deployments.SummarizeDurations([]time.Duration{90 * time.Second}))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(jsonOutput).To(MatchJSON(`{"count": 1, "min_seconds": 90, "median_seconds": 90, "p95_seconds": 90, "max_seconds": 90}`))
+	})
+})
@@ -0,0 +1,76 @@
+package deployments
+
+import (
+	boshdir "github.com/cloudfoundry/bosh-cli/director"
+	boshlog "github.com/cloudfoundry/bosh-utils/logger"
+)
+
+type DeployOutcome struct {
+	Successful   int     `json:"successful"`
+	Failed       int     `json:"failed"`
+	FailureRatio float64 `json:"failure_ratio"`
+}
+
+// DeployOutcomes counts successful and failed deploys in a single walk of the
+// events.
+func (d *DeployCounter) DeployOutcomes(period string, itemsPerPage int, repaveUser string, deployment string) (map[string]DeployOutcome, error) {
+	if _, err := cachedUserMatcher(repaveUser); err != nil {
+		return nil, err
+	}
+
+	logger := boshlog.NewLogger(boshlog.LevelError)
+
+	reportingPeriod, err := d.reportingPeriod(period)
+	if err != nil {
+		return nil, err
+	}
+	opts := createCalendarOpts(reportingPeriod, deployment)
+
+	eventSource, err := createEventSource(d, logger, itemsPerPage)
+	if err != nil {
+		return nil, err
+	}
+
+	successful := make(map[string]int)
+	failed := make(map[string]int)
+	err = reduceDeploymentsToCount(eventSource, []boshdir.Event{}, opts, itemsPerPage, func(events []boshdir.Event) {
+		deploymentEventCount(events, &successful, repaveUser)
+		failedDeploymentEventCount(events, &failed, repaveUser)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return NewDeployOutcomes(successful, failed), nil
+}
+
+func NewDeployOutcomes(successful map[string]int, failed map[string]int) map[string]DeployOutcome {
+	outcomes := make(map[string]DeployOutcome)
+
+	for deployment, count := range successful {
+		outcome := outcomes[deployment]
+		outcome.Successful = count
+		outcomes[deployment] = outcome
+	}
+
+	for deployment, count := range failed {
+		outcome := outcomes[deployment]
+		outcome.Failed = count
+		outcomes[deployment] = outcome
+	}
+
+	for deployment, outcome := range outcomes {
+		outcome.FailureRatio = FailureRatio(outcome.Successful, outcome.Failed)
+		outcomes[deployment] = outcome
+	}
+
+	return outcomes
+}
+
+func FailureRatio(successful int, failed int) float64 {
+	if successful+failed == 0 {
+		return 0
+	}
+
+	return float64(failed) / float64(successful+failed)
+}
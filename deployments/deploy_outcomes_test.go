@@ -0,0 +1,51 @@
+package deployments_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/pivotal-cloudops/bosh-stats/deployments"
+)
+
+var _ = Describe("DeployOutcomes", func() {
+	events := `
+	[
+		{"id": "4", "action": "update", "user": "admin", "object_type": "deployment", "deployment": "cf", "context": {"before": {}, "after": {}}},
+		{"id": "3", "action": "update", "user": "admin", "error": "failed", "object_type": "deployment", "deployment": "cf"},
+		{"id": "2", "action": "update", "user": "repave", "error": "failed", "object_type": "deployment", "deployment": "diego"},
+		{"id": "1", "action": "update", "user": "admin", "error": "failed", "object_type": "deployment", "deployment": "diego"}
+	]`
+
+	fake := serveDirector(serveEvents(events, "before_time=1448927999&after_time=1446336000"))
+
+	It("counts successful and failed deploys from a single walk of the events", func() {
+		outcomes, err := fake.deployCounter.DeployOutcomes("2015/11", 999, "repave", "")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(fake.director.ReceivedRequests()).To(HaveLen(1))
+
+		Expect(outcomes).To(Equal(map[string]deployments.DeployOutcome{
+			"cf":    {Successful: 1, Failed: 1, FailureRatio: 0.5},
+			"diego": {Successful: 0, Failed: 1, FailureRatio: 1},
+		}))
+	})
+})
+
+var _ = Describe("NewDeployOutcomes", func() {
+	It("combines successful and failed deploys into a failure ratio per deployment", func() {
+		outcomes := deployments.NewDeployOutcomes(
+			map[string]int{"cf": 3, "diego": 2},
+			map[string]int{"cf": 1, "mysql": 2},
+		)
+
+		Expect(outcomes).To(Equal(map[string]deployments.DeployOutcome{
+			"cf":    {Successful: 3, Failed: 1, FailureRatio: 0.25},
+			"diego": {Successful: 2, Failed: 0, FailureRatio: 0},
+			"mysql": {Successful: 0, Failed: 2, FailureRatio: 1},
+		}))
+	})
+})
+
+var _ = Describe("FailureRatio", func() {
+	It("is 0 when there were no deploys", func() {
+		Expect(deployments.FailureRatio(0, 0)).To(Equal(0.0))
+	})
+})
@@ -3,7 +3,6 @@ package deployments
 import (
 	"errors"
 	"fmt"
-	"regexp"
 	"strconv"
 	"strings"
 	"time"
@@ -12,31 +11,100 @@ import (
 	boshdir "github.com/cloudfoundry/bosh-cli/director"
 	boshuaa "github.com/cloudfoundry/bosh-cli/uaa"
 	boshlog "github.com/cloudfoundry/bosh-utils/logger"
-	"github.com/jinzhu/now"
 )
 
 type DeployCounter struct {
-	DirectorURL     string
-	UaaURL          string
-	UaaClientID     string
-	UaaClientSecret string
-	CaCert          string
+	DirectorURL     string         `json:"director_url"`
+	UaaURL          string         `json:"uaa_url"`
+	UaaClientID     string         `json:"uaa_client_id"`
+	UaaClientSecret string         `json:"uaa_client_secret"`
+	CaCert          string         `json:"ca_cert"`
+	CacheDir        string         `json:"cache_dir"`
+	EventsFile      string         `json:"events_file"`
+	Timezone        *time.Location `json:"-"`
 }
 
-func (d *DeployCounter) SuccessfulDeploys(calendarMonth string, itemsPerPage int, repaveUser string, runningCount *map[string]int, deployment string) error {
+type eventLister interface {
+	Events(boshdir.EventsFilter) ([]boshdir.Event, error)
+}
+
+func (d *DeployCounter) SuccessfulDeploys(period string, itemsPerPage int, repaveUser string, runningCount *map[string]int, deployment string) error {
+	if _, err := cachedUserMatcher(repaveUser); err != nil {
+		return err
+	}
+
 	logger := boshlog.NewLogger(boshlog.LevelError)
 
-	directorClient, err := createDirectorClient(d, logger)
+	reportingPeriod, err := d.reportingPeriod(period)
+	if err != nil {
+		return err
+	}
+	opts := createCalendarOpts(reportingPeriod, deployment)
+
+	eventSource, err := createEventSource(d, logger, itemsPerPage)
+	if err != nil {
+		return err
+	}
+
+	err = reduceDeploymentsToCount(eventSource, []boshdir.Event{}, opts, itemsPerPage, func(events []boshdir.Event) {
+		deploymentEventCount(events, runningCount, repaveUser)
+	})
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (d *DeployCounter) FailedDeploys(period string, itemsPerPage int, repaveUser string, runningCount *map[string]int, deployment string) error {
+	if _, err := cachedUserMatcher(repaveUser); err != nil {
+		return err
+	}
+
+	logger := boshlog.NewLogger(boshlog.LevelError)
+
+	reportingPeriod, err := d.reportingPeriod(period)
+	if err != nil {
+		return err
+	}
+	opts := createCalendarOpts(reportingPeriod, deployment)
+
+	eventSource, err := createEventSource(d, logger, itemsPerPage)
+	if err != nil {
+		return err
+	}
+
+	err = reduceDeploymentsToCount(eventSource, []boshdir.Event{}, opts, itemsPerPage, func(events []boshdir.Event) {
+		failedDeploymentEventCount(events, runningCount, repaveUser)
+	})
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (d *DeployCounter) SuccessfulDeploysByUser(period string, itemsPerPage int, repaveUser string, runningCount *map[string]map[string]int, deployment string) error {
+	if _, err := cachedUserMatcher(repaveUser); err != nil {
+		return err
+	}
+
+	logger := boshlog.NewLogger(boshlog.LevelError)
+
+	reportingPeriod, err := d.reportingPeriod(period)
 	if err != nil {
 		return err
 	}
+	opts := createCalendarOpts(reportingPeriod, deployment)
 
-	opts, err := createCalendarOpts(calendarMonth, deployment)
+	eventSource, err := createEventSource(d, logger, itemsPerPage)
 	if err != nil {
 		return err
 	}
 
-	err = reduceDeploymentsToCount(directorClient, []boshdir.Event{}, opts, itemsPerPage, runningCount, repaveUser)
+	err = reduceDeploymentsToCount(eventSource, []boshdir.Event{}, opts, itemsPerPage, func(events []boshdir.Event) {
+		deploymentEventCountByUser(events, runningCount, repaveUser)
+	})
 	if err != nil {
 		return err
 	}
@@ -44,29 +112,59 @@ func (d *DeployCounter) SuccessfulDeploys(calendarMonth string, itemsPerPage int
 	return nil
 }
 
+func (d *DeployCounter) SuccessfulDeploysSince(lastEventID string, itemsPerPage int, repaveUser string, runningCount *map[string]map[string]int, deployment string) (string, error) {
+	if _, err := cachedUserMatcher(repaveUser); err != nil {
+		return lastEventID, err
+	}
+
+	logger := boshlog.NewLogger(boshlog.LevelError)
+
+	eventSource, err := createEventSource(d, logger, itemsPerPage)
+	if err != nil {
+		return lastEventID, err
+	}
+
+	opts := boshdir.EventsFilter{Deployment: deployment}
+	newestEventID := lastEventID
+
+	err = reduceNewEvents(eventSource, []boshdir.Event{}, opts, itemsPerPage, lastEventID, func(events []boshdir.Event) {
+		for _, event := range events {
+			if isNewerEvent(event, newestEventID) {
+				newestEventID = event.ID()
+			}
+		}
+		deploymentEventCountByUser(events, runningCount, repaveUser)
+	})
+	if err != nil {
+		return lastEventID, err
+	}
+
+	return newestEventID, nil
+}
+
 func (d *DeployCounter) DeployDate(release string, version string, itemsPerPage int) (time.Time, error) {
 	logger := boshlog.NewLogger(boshlog.LevelError)
 
-	directorClient, err := createDirectorClient(d, logger)
+	eventSource, err := createEventSource(d, logger, itemsPerPage)
 	if err != nil {
 		return time.Time{}, err
 	}
 
 	opts := boshdir.EventsFilter{}
 
-	deployDate, err := reduceDeployDate(directorClient, []boshdir.Event{}, opts, itemsPerPage, release, version)
+	deployDate, err := reduceDeployDate(eventSource, []boshdir.Event{}, opts, itemsPerPage, release, version)
 	if err != nil {
 		return time.Time{}, err
 	}
 	return deployDate, err
 }
 
-func reduceDeployDate(directorClient boshdir.Director, events []boshdir.Event, opts boshdir.EventsFilter, itemsPerPage int, release string, version string) (time.Time, error) {
+func reduceDeployDate(eventSource eventLister, events []boshdir.Event, opts boshdir.EventsFilter, itemsPerPage int, release string, version string) (time.Time, error) {
 	newOpts := opts
 	if len(events) != 0 {
 		newOpts.BeforeID = events[len(events)-1].ID()
 	}
-	newEvents, err := directorClient.Events(newOpts)
+	newEvents, err := eventSource.Events(newOpts)
 	if err != nil {
 		return time.Time{}, err
 	}
@@ -79,7 +177,7 @@ func reduceDeployDate(directorClient boshdir.Director, events []boshdir.Event, o
 	if found_ok {
 		return date, nil
 	} else {
-		return reduceDeployDate(directorClient, newEvents, newOpts, itemsPerPage, release, version)
+		return reduceDeployDate(eventSource, newEvents, newOpts, itemsPerPage, release, version)
 	}
 }
 
@@ -92,7 +190,7 @@ func findDeployTime(events []boshdir.Event, release string, version string) (tim
 	return time.Time{}, false
 }
 
-func reduceDeploymentsToCount(directorClient boshdir.Director, events []boshdir.Event, opts boshdir.EventsFilter, itemsPerPage int, runningCount *map[string]int, repaveUser string) error {
+func reduceDeploymentsToCount(eventSource eventLister, events []boshdir.Event, opts boshdir.EventsFilter, itemsPerPage int, countEvents func([]boshdir.Event)) error {
 	if len(events) > 0 && len(events) < itemsPerPage {
 		return nil
 	}
@@ -101,7 +199,7 @@ func reduceDeploymentsToCount(directorClient boshdir.Director, events []boshdir.
 	if len(events) != 0 {
 		newOpts.BeforeID = events[len(events)-1].ID()
 	}
-	newEvents, err := directorClient.Events(newOpts)
+	newEvents, err := eventSource.Events(newOpts)
 	if err != nil {
 		return err
 	}
@@ -110,8 +208,8 @@ func reduceDeploymentsToCount(directorClient boshdir.Director, events []boshdir.
 		return nil
 	}
 
-	deploymentEventCount(newEvents, runningCount, repaveUser)
-	return reduceDeploymentsToCount(directorClient, newEvents, newOpts, itemsPerPage, runningCount, repaveUser)
+	countEvents(newEvents)
+	return reduceDeploymentsToCount(eventSource, newEvents, newOpts, itemsPerPage, countEvents)
 }
 
 func deploymentEventCount(events []boshdir.Event, runningCount *map[string]int, repaveUser string) {
@@ -123,6 +221,80 @@ func deploymentEventCount(events []boshdir.Event, runningCount *map[string]int,
 	}
 }
 
+func reduceNewEvents(eventSource eventLister, events []boshdir.Event, opts boshdir.EventsFilter, itemsPerPage int, lastEventID string, countEvents func([]boshdir.Event)) error {
+	if len(events) > 0 && len(events) < itemsPerPage {
+		return nil
+	}
+
+	newOpts := opts
+	if len(events) != 0 {
+		newOpts.BeforeID = events[len(events)-1].ID()
+	}
+	newEvents, err := eventSource.Events(newOpts)
+	if err != nil {
+		return err
+	}
+
+	if len(newEvents) == 0 {
+		return nil
+	}
+
+	unseenEvents := []boshdir.Event{}
+	for _, event := range newEvents {
+		if isNewerEvent(event, lastEventID) {
+			unseenEvents = append(unseenEvents, event)
+		}
+	}
+
+	countEvents(unseenEvents)
+
+	// Events are returned newest first, so the first event we have already
+	// seen means every following page has been counted before.
+	if len(unseenEvents) < len(newEvents) {
+		return nil
+	}
+	return reduceNewEvents(eventSource, newEvents, newOpts, itemsPerPage, lastEventID, countEvents)
+}
+
+func failedDeploymentEventCount(events []boshdir.Event, runningCount *map[string]int, repaveUser string) {
+	for _, event := range events {
+		if isFailedDeployment(event) && IsNotRepaveUser(event, repaveUser) {
+			deploymentName := event.DeploymentName()
+			(*runningCount)[deploymentName] += 1
+		}
+	}
+}
+
+func deploymentEventCountByUser(events []boshdir.Event, runningCount *map[string]map[string]int, repaveUser string) {
+	for _, event := range events {
+		if isDeployment(event) && IsNotRepaveUser(event, repaveUser) {
+			deploymentName := event.DeploymentName()
+			if (*runningCount)[deploymentName] == nil {
+				(*runningCount)[deploymentName] = make(map[string]int)
+			}
+			(*runningCount)[deploymentName][event.User()] += 1
+		}
+	}
+}
+
+func isNewerEvent(event boshdir.Event, eventID string) bool {
+	if eventID == "" {
+		return true
+	}
+
+	return isNewerEventID(event.ID(), eventID)
+}
+
+func isNewerEventID(eventID string, otherEventID string) bool {
+	id, idErr := strconv.Atoi(eventID)
+	otherID, otherIDErr := strconv.Atoi(otherEventID)
+	if idErr != nil || otherIDErr != nil {
+		return eventID > otherEventID
+	}
+
+	return id > otherID
+}
+
 func createDirectorClient(d *DeployCounter, logger boshlog.Logger) (boshdir.Director, error) {
 	uaaClient, err := createUaaClient(d, logger)
 	if err != nil {
@@ -143,6 +315,57 @@ func createDirectorClient(d *DeployCounter, logger boshlog.Logger) (boshdir.Dire
 	return directorClient, nil
 }
 
+func createEventSource(d *DeployCounter, logger boshlog.Logger, itemsPerPage int) (eventLister, error) {
+	if d.EventsFile != "" {
+		return OpenEventsFile(d.EventsFile)
+	}
+
+	directorClient, err := createDirectorClient(d, logger)
+	if err != nil {
+		return nil, err
+	}
+
+	return directorEventSource(d, directorClient, itemsPerPage)
+}
+
+// createEventSourceAndDirector is for reports that also look up tasks. The
+// director is nil when reading events from a file.
+func createEventSourceAndDirector(d *DeployCounter, logger boshlog.Logger, itemsPerPage int) (eventLister, boshdir.Director, error) {
+	if d.EventsFile != "" {
+		eventSource, err := OpenEventsFile(d.EventsFile)
+		return eventSource, nil, err
+	}
+
+	directorClient, err := createDirectorClient(d, logger)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	eventSource, err := directorEventSource(d, directorClient, itemsPerPage)
+	if err != nil {
+		return nil, nil, err
+	}
+	return eventSource, directorClient, nil
+}
+
+func directorEventSource(d *DeployCounter, directorClient boshdir.Director, itemsPerPage int) (eventLister, error) {
+	if d.CacheDir == "" {
+		return directorClient, nil
+	}
+
+	cache, err := cachedEventCache(d.CacheDir, d.DirectorURL)
+	if err != nil {
+		return nil, err
+	}
+
+	err = cache.Sync(directorClient, itemsPerPage)
+	if err != nil {
+		return nil, err
+	}
+
+	return cache, nil
+}
+
 func createUaaClient(d *DeployCounter, logger boshlog.Logger) (boshuaa.UAA, error) {
 	factory := boshuaa.NewFactory(logger)
 	uaaConfig, err := boshuaa.NewConfigFromURL(d.UaaURL)
@@ -162,38 +385,33 @@ func createUaaClient(d *DeployCounter, logger boshlog.Logger) (boshuaa.UAA, erro
 	return uaaClient, nil
 }
 
-func createCalendarOpts(calendarMonth string, deployment string) (boshdir.EventsFilter, error) {
-	calendarMonthComponents := strings.Split(calendarMonth, "/")
-	year, err := strconv.Atoi(calendarMonthComponents[0])
-	if err != nil {
-		return boshdir.EventsFilter{}, err
-	}
-	month, err := strconv.Atoi(calendarMonthComponents[1])
-	if err != nil {
-		return boshdir.EventsFilter{}, err
-	}
-	startTime := now.New(time.Date(year, time.Month(month), 1, 0, 0, 0, 0, time.UTC))
-	endTime := startTime.EndOfMonth()
+func (d *DeployCounter) reportingPeriod(period string) (Period, error) {
+	return ParsePeriod(period, d.Timezone, time.Now())
+}
 
+func createCalendarOpts(period Period, deployment string) boshdir.EventsFilter {
 	var opts boshdir.EventsFilter
 
 	if deployment == "" {
 		opts = boshdir.EventsFilter{
-			Before: fmt.Sprintf("%d", endTime.Unix()),
-			After:  fmt.Sprintf("%d", startTime.Unix()),
+			Before: fmt.Sprintf("%d", period.End.Unix()),
+			After:  fmt.Sprintf("%d", period.Start.Unix()),
 		}
 	} else {
 		opts = boshdir.EventsFilter{
-			Before:     fmt.Sprintf("%d", endTime.Unix()),
-			After:      fmt.Sprintf("%d", startTime.Unix()),
+			Before:     fmt.Sprintf("%d", period.End.Unix()),
+			After:      fmt.Sprintf("%d", period.Start.Unix()),
 			Deployment: deployment,
 		}
 	}
-	return opts, nil
+	return opts
 }
 
+// IsNotRepaveUser treats an invalid repaveUser as matching nobody. The
+// DeployCounter methods return its parse error before reading any events.
 func IsNotRepaveUser(event boshdir.Event, repaveUser string) bool {
-	if event.User() == repaveUser {
+	matcher, err := cachedUserMatcher(repaveUser)
+	if err == nil && matcher.Matches(event.User()) {
 		return false
 	}
 
@@ -201,73 +419,89 @@ func IsNotRepaveUser(event boshdir.Event, repaveUser string) bool {
 }
 
 func IsReleaseUpdate(event boshdir.Event, release string, version string) bool {
-	context := event.Context()
-	context_before, ok := context["before"].(map[string]interface{})
+	return isVersionUpdate(event, "releases", release, version)
+}
 
-	if ok != true {
+func IsStemcellUpdate(event boshdir.Event, stemcell string, version string) bool {
+	return isVersionUpdate(event, "stemcells", stemcell, version)
+}
+
+func isVersionUpdate(event boshdir.Event, key string, name string, version string) bool {
+	versionsBefore, ok := contextVersions(event, "before", key, name)
+	if !ok || len(versionsBefore) == 0 {
 		return false
 	}
-	context_after, ok := context["after"].(map[string]interface{})
 
-	if ok != true {
+	versionsAfter, ok := contextVersions(event, "after", key, name)
+	if !ok || !containsVersion(versionsAfter, version) {
 		return false
 	}
 
-	releases_before, ok := context_before["releases"].([]interface{})
-	if ok != true {
-		return false
+	var latestSemverBefore semver.Version
+	for _, versionBefore := range versionsBefore {
+		semverBefore, err := semver.ParseTolerant(versionBefore)
+		if err != nil {
+			continue
+		}
+
+		if latestSemverBefore.LT(semverBefore) {
+			latestSemverBefore = semverBefore
+		}
 	}
 
-	releases_after, ok := context_after["releases"].([]interface{})
-	if ok != true {
+	semverAfter, err := semver.ParseTolerant(version)
+	if err != nil {
 		return false
 	}
 
-	var version_before, version_after string
-	var semver_before, semver_after, latest_semver_before semver.Version
-	var err error
+	return semverAfter.GT(latestSemverBefore)
+}
 
-	re := regexp.MustCompile(fmt.Sprintf("^%s\\/(.*)", release))
+// contextVersions returns the versions of name listed under key ("releases"
+// or "stemcells") in the before or after side of a deploy event's context.
+func contextVersions(event boshdir.Event, side string, key string, name string) ([]string, bool) {
+	entries, ok := contextEntries(event, side, key)
+	if !ok {
+		return nil, false
+	}
+	return entries[name], true
+}
 
-	for _, release_before := range releases_before {
-		matches := re.FindStringSubmatch(release_before.(string))
-		if matches != nil {
-			version_before = matches[1]
-			semver_before, err = semver.Parse(version_before)
-			if err != nil {
-				semver_before, err = semver.Parse(version_before + ".0.0")
-				if err != nil {
-					panic(err)
-				}
-			}
+// contextEntries maps each release or stemcell name listed under key to its
+// versions, from entries such as "cf/250".
+func contextEntries(event boshdir.Event, side string, key string) (map[string][]string, bool) {
+	sideContext, ok := event.Context()[side].(map[string]interface{})
+	if !ok {
+		return nil, false
+	}
 
-			if latest_semver_before.LT(semver_before) {
-				latest_semver_before = semver_before
-			}
-		}
+	entries, ok := sideContext[key].([]interface{})
+	if !ok {
+		return nil, false
 	}
 
-	for _, release_after := range releases_after {
-		matches := re.FindStringSubmatch(release_after.(string))
-		if matches != nil && matches[1] == version {
-			version_after = matches[1]
-			break
+	versions := make(map[string][]string)
+	for _, entry := range entries {
+		nameAndVersion, ok := entry.(string)
+		if !ok {
+			continue
 		}
-	}
 
-	if version_before == "" || version_after == "" {
-		return false
+		parts := strings.SplitN(nameAndVersion, "/", 2)
+		if len(parts) == 2 {
+			versions[parts[0]] = append(versions[parts[0]], parts[1])
+		}
 	}
+	return versions, true
+}
 
-	semver_after, err = semver.Parse(version_after)
-	if err != nil {
-		semver_after, err = semver.Parse(version_after + ".0.0")
-		if err != nil {
-			panic(err)
+func containsVersion(versions []string, version string) bool {
+	for _, v := range versions {
+		if v == version {
+			return true
 		}
 	}
-
-	return semver_after.GT(latest_semver_before)
+	return false
 }
 
 func isDeployment(event boshdir.Event) bool {
@@ -276,3 +510,9 @@ func isDeployment(event boshdir.Event) bool {
 		event.Error() == "" &&
 		len(event.Context()) > 0
 }
+
+func isFailedDeployment(event boshdir.Event) bool {
+	return event.ObjectType() == "deployment" &&
+		(event.Action() == "create" || event.Action() == "update") &&
+		event.Error() != ""
+}
@@ -1,7 +1,6 @@
 package deployments_test
 
 import (
-	"crypto/tls"
 	"net/http"
 	"time"
 
@@ -18,17 +17,6 @@ var _ = Describe("counting bosh deployments and get deploy date", func() {
 		director *ghttp.Server
 	)
 
-	startHttpsServer := func(cert, key string) *ghttp.Server {
-		server := ghttp.NewUnstartedServer()
-		keypair, err := tls.X509KeyPair([]byte(cert), []byte(key))
-		Expect(err).NotTo(HaveOccurred())
-		server.HTTPTestServer.TLS = &tls.Config{
-			Certificates: []tls.Certificate{keypair},
-		}
-		server.HTTPTestServer.StartTLS()
-		return server
-	}
-
 	BeforeEach(func() {
 		director = startHttpsServer(validCert, validKey)
 		uaa = startHttpsServer(validCert, validKey)
@@ -215,6 +203,25 @@ var _ = Describe("counting bosh deployments and get deploy date", func() {
 			Expect(runningCount).To(Equal(expectedRunningcount))
 		})
 
+		It("returns the number of failed deploys in the provided month", func() {
+			deployCounter := &deployments.DeployCounter{
+				DirectorURL:     director.URL(),
+				UaaURL:          uaa.URL(),
+				UaaClientID:     "some-client",
+				UaaClientSecret: "itsasecret",
+				CaCert:          validCACert,
+			}
+
+			runningCount := make(map[string]int)
+			expectedRunningcount := map[string]int{
+				"bla1": 1,
+			}
+			err := deployCounter.FailedDeploys("2015/11", 999, "repave", &runningCount, "")
+			Expect(director.ReceivedRequests()).To(HaveLen(1))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(runningCount).To(Equal(expectedRunningcount))
+		})
+
 		It("returns the date of deploy happens to update the release", func() {
 			statusOK := http.StatusOK
 			events := `[]`
@@ -450,6 +457,123 @@ var _ = Describe("counting bosh deployments and get deploy date", func() {
 			Expect(runningCount).To(Equal(expectedRunningcount))
 		})
 
+		It("counts successful deploys by user in the provided month, filtering out a list of users", func() {
+			director.AppendHandlers(
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest("GET", "/events", "before_time=1448927999&after_time=1446336000"),
+					ghttp.RespondWith(statusOK, eventsPage1),
+				),
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest("GET", "/events", "after_time=1446336000&before_id=2&before_time=1448927999"),
+					ghttp.RespondWith(statusOK, eventsPage2),
+				),
+			)
+
+			deployCounter := &deployments.DeployCounter{
+				DirectorURL:     director.URL(),
+				UaaURL:          uaa.URL(),
+				UaaClientID:     "some-client",
+				UaaClientSecret: "itsasecret",
+				CaCert:          validCACert,
+			}
+			runningCount := make(map[string]map[string]int)
+			expectedRunningcount := map[string]map[string]int{
+				"bla1": {"not-repave": 1},
+				"bla2": {"not-repave": 1},
+			}
+
+			err := deployCounter.SuccessfulDeploysByUser("2015/11", 3, "repave, /^MyCustom/", &runningCount, "")
+			Expect(director.ReceivedRequests()).To(HaveLen(2))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(runningCount).To(Equal(expectedRunningcount))
+		})
+
+		It("filters out failed deploys made by the repave user given", func() {
+			director.AppendHandlers(
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest("GET", "/events", "before_time=1448927999&after_time=1446336000"),
+					ghttp.RespondWith(statusOK, eventsPage1),
+				),
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest("GET", "/events", "after_time=1446336000&before_id=2&before_time=1448927999"),
+					ghttp.RespondWith(statusOK, eventsPage2),
+				),
+			)
+
+			deployCounter := &deployments.DeployCounter{
+				DirectorURL:     director.URL(),
+				UaaURL:          uaa.URL(),
+				UaaClientID:     "some-client",
+				UaaClientSecret: "itsasecret",
+				CaCert:          validCACert,
+			}
+			runningCount := make(map[string]int)
+
+			err := deployCounter.FailedDeploys("2015/11", 3, "not-repave", &runningCount, "")
+			Expect(director.ReceivedRequests()).To(HaveLen(2))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(runningCount).To(Equal(map[string]int{}))
+		})
+
+		It("counts successful deploys by user and returns the newest event ID", func() {
+			director.AppendHandlers(
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest("GET", "/events", ""),
+					ghttp.RespondWith(statusOK, eventsPage1),
+				),
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest("GET", "/events", "before_id=2"),
+					ghttp.RespondWith(statusOK, eventsPage2),
+				),
+			)
+
+			deployCounter := &deployments.DeployCounter{
+				DirectorURL:     director.URL(),
+				UaaURL:          uaa.URL(),
+				UaaClientID:     "some-client",
+				UaaClientSecret: "itsasecret",
+				CaCert:          validCACert,
+			}
+			runningCount := make(map[string]map[string]int)
+			expectedRunningcount := map[string]map[string]int{
+				"bla1": {"not-repave": 1},
+				"bla2": {"not-repave": 1},
+			}
+
+			newestEventID, err := deployCounter.SuccessfulDeploysSince("", 3, "MyCustomRepaveUserInProd", &runningCount, "")
+			Expect(director.ReceivedRequests()).To(HaveLen(2))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(newestEventID).To(Equal("4"))
+			Expect(runningCount).To(Equal(expectedRunningcount))
+		})
+
+		It("stops paging once it reaches an event it has already seen", func() {
+			director.AppendHandlers(
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest("GET", "/events", ""),
+					ghttp.RespondWith(statusOK, eventsPage1),
+				),
+			)
+
+			deployCounter := &deployments.DeployCounter{
+				DirectorURL:     director.URL(),
+				UaaURL:          uaa.URL(),
+				UaaClientID:     "some-client",
+				UaaClientSecret: "itsasecret",
+				CaCert:          validCACert,
+			}
+			runningCount := make(map[string]map[string]int)
+			expectedRunningcount := map[string]map[string]int{
+				"bla1": {"not-repave": 1},
+			}
+
+			newestEventID, err := deployCounter.SuccessfulDeploysSince("3", 3, "repave", &runningCount, "")
+			Expect(director.ReceivedRequests()).To(HaveLen(1))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(newestEventID).To(Equal("4"))
+			Expect(runningCount).To(Equal(expectedRunningcount))
+		})
+
 		It("find the deploy date of cf/123", func() {
 			deployCounter := &deployments.DeployCounter{
 				DirectorURL:     director.URL(),
@@ -610,6 +734,24 @@ var _ = Describe("counting bosh deployments and get deploy date", func() {
 		})
 	})
 
+	Context("using a bad period", func() {
+		It("returns a validation error without calling the director", func() {
+			deployCounter := &deployments.DeployCounter{
+				DirectorURL:     director.URL(),
+				UaaURL:          uaa.URL(),
+				UaaClientID:     "some-client",
+				UaaClientSecret: "itsasecret",
+				CaCert:          validCACert,
+			}
+
+			runningCount := make(map[string]int)
+
+			err := deployCounter.SuccessfulDeploys("2015", 999, "repave", &runningCount, "")
+			Expect(director.ReceivedRequests()).To(HaveLen(0))
+			Expect(err).To(MatchError(ContainSubstring(`invalid period "2015"`)))
+		})
+	})
+
 	Context("error returned from UAA", func() {
 		BeforeEach(func() {
 			statusError := http.StatusInternalServerError
@@ -660,6 +802,52 @@ var _ = Describe("#isNotRepaveUser", func() {
 		repaveUser := deployments.IsNotRepaveUser(event, "repave")
 		Expect(repaveUser).To(Equal(false))
 	})
+
+	It("returns false if the event's user is in the list or matches a pattern", func() {
+		var event = new(directorfakes.FakeEvent)
+		event.UserReturns("ci-cf-pipeline")
+
+		Expect(deployments.IsNotRepaveUser(event, "repave,ci-cf-pipeline")).To(Equal(false))
+		Expect(deployments.IsNotRepaveUser(event, "repave, /^ci-/")).To(Equal(false))
+		Expect(deployments.IsNotRepaveUser(event, "repave, /^cf-/")).To(Equal(true))
+	})
+})
+
+var _ = Describe("#IsReleaseUpdate", func() {
+	It("compares versions with fewer than three components", func() {
+		var event = new(directorfakes.FakeEvent)
+		event.ContextReturns(map[string]interface{}{
+			"before": map[string]interface{}{"releases": []interface{}{"garden-runc/1.9"}},
+			"after":  map[string]interface{}{"releases": []interface{}{"garden-runc/1.10"}},
+		})
+
+		Expect(deployments.IsReleaseUpdate(event, "garden-runc", "1.10")).To(Equal(true))
+		Expect(deployments.IsReleaseUpdate(event, "garden-runc", "1.9")).To(Equal(false))
+	})
+
+	It("returns false instead of panicking on versions that are not semver", func() {
+		var event = new(directorfakes.FakeEvent)
+		event.ContextReturns(map[string]interface{}{
+			"before": map[string]interface{}{"releases": []interface{}{"cf/latest"}},
+			"after":  map[string]interface{}{"releases": []interface{}{"cf/0+dev.1"}},
+		})
+
+		Expect(deployments.IsReleaseUpdate(event, "cf", "0+dev.1")).To(Equal(false))
+	})
+})
+
+var _ = Describe("#IsStemcellUpdate", func() {
+	It("compares the stemcell versions of the deploy", func() {
+		var event = new(directorfakes.FakeEvent)
+		event.ContextReturns(map[string]interface{}{
+			"before": map[string]interface{}{"stemcells": []interface{}{"bosh-aws-xen-hvm-ubuntu-trusty-go_agent/3421.9"}},
+			"after":  map[string]interface{}{"stemcells": []interface{}{"bosh-aws-xen-hvm-ubuntu-trusty-go_agent/3421.11"}},
+		})
+
+		Expect(deployments.IsStemcellUpdate(event, "bosh-aws-xen-hvm-ubuntu-trusty-go_agent", "3421.11")).To(Equal(true))
+		Expect(deployments.IsStemcellUpdate(event, "bosh-aws-xen-hvm-ubuntu-trusty-go_agent", "3421.9")).To(Equal(false))
+		Expect(deployments.IsReleaseUpdate(event, "bosh-aws-xen-hvm-ubuntu-trusty-go_agent", "3421.11")).To(Equal(false))
+	})
 })
 
 var validCert = `-----BEGIN CERTIFICATE-----
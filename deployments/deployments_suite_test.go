@@ -1,8 +1,13 @@
 package deployments_test
 
 import (
+	"crypto/tls"
+	"net/http"
+
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
+	"github.com/onsi/gomega/ghttp"
+	"github.com/pivotal-cloudops/bosh-stats/deployments"
 
 	"testing"
 )
@@ -11,3 +16,64 @@ func TestDeployments(t *testing.T) {
 	RegisterFailHandler(Fail)
 	RunSpecs(t, "Deployments Suite")
 }
+
+func startHttpsServer(cert, key string) *ghttp.Server {
+	server := ghttp.NewUnstartedServer()
+	keypair, err := tls.X509KeyPair([]byte(cert), []byte(key))
+	Expect(err).NotTo(HaveOccurred())
+	server.HTTPTestServer.TLS = &tls.Config{
+		Certificates: []tls.Certificate{keypair},
+	}
+	server.HTTPTestServer.StartTLS()
+	return server
+}
+
+type fakeDirector struct {
+	uaa           *ghttp.Server
+	director      *ghttp.Server
+	deployCounter *deployments.DeployCounter
+}
+
+// serveDirector starts a director and its UAA before each spec of the calling
+// container and closes them after it. The UAA hands some-client a token on
+// every request, the director serves the given handlers in order and
+// deployCounter talks to both.
+func serveDirector(handlers ...http.HandlerFunc) *fakeDirector {
+	fake := &fakeDirector{}
+
+	BeforeEach(func() {
+		statusOK := http.StatusOK
+		token := map[string]string{"token": "itsatoken"}
+
+		fake.director = startHttpsServer(validCert, validKey)
+		fake.uaa = startHttpsServer(validCert, validKey)
+
+		fake.uaa.RouteToHandler("POST", "/oauth/token", ghttp.CombineHandlers(
+			ghttp.VerifyBasicAuth("some-client", "itsasecret"),
+			ghttp.RespondWithJSONEncodedPtr(&statusOK, &token),
+		))
+		fake.director.AppendHandlers(handlers...)
+
+		fake.deployCounter = &deployments.DeployCounter{
+			DirectorURL:     fake.director.URL(),
+			UaaURL:          fake.uaa.URL(),
+			UaaClientID:     "some-client",
+			UaaClientSecret: "itsasecret",
+			CaCert:          validCACert,
+		}
+	})
+
+	AfterEach(func() {
+		fake.director.Close()
+		fake.uaa.Close()
+	})
+
+	return fake
+}
+
+func serveEvents(events string, rawQuery ...string) http.HandlerFunc {
+	return ghttp.CombineHandlers(
+		ghttp.VerifyRequest("GET", "/events", rawQuery...),
+		ghttp.RespondWith(http.StatusOK, events),
+	)
+}
@@ -0,0 +1,205 @@
+package deployments
+
+import (
+	"encoding/json"
+	"strings"
+	"time"
+
+	boshdir "github.com/cloudfoundry/bosh-cli/director"
+	boshlog "github.com/cloudfoundry/bosh-utils/logger"
+)
+
+type DORAMetrics struct {
+	Deploys           int
+	DeploysPerDay     float64
+	LeadTime          time.Duration
+	LeadTimeSamples   int
+	ChangeFailureRate float64
+	TimeToRestore     time.Duration
+	Restores          int
+}
+
+type DORAReport struct {
+	Overall      DORAMetrics
+	ByDeployment map[string]DORAMetrics
+}
+
+type releaseUpload struct {
+	name      string
+	version   string
+	timestamp time.Time
+}
+
+type doraAccumulator struct {
+	successful      int
+	failed          int
+	leadTimes       []time.Duration
+	restoreTimes    []time.Duration
+	failingSince    time.Time
+	deployedUploads map[int]bool
+}
+
+func (d *DeployCounter) DORAMetrics(period string, itemsPerPage int, repaveUser string, deployment string) (DORAReport, error) {
+	if _, err := cachedUserMatcher(repaveUser); err != nil {
+		return DORAReport{}, err
+	}
+
+	logger := boshlog.NewLogger(boshlog.LevelError)
+
+	reportingPeriod, err := d.reportingPeriod(period)
+	if err != nil {
+		return DORAReport{}, err
+	}
+
+	// Release uploads do not belong to a deployment, so the deployment is
+	// filtered here rather than by the director.
+	opts := createCalendarOpts(reportingPeriod, "")
+
+	eventSource, err := createEventSource(d, logger, itemsPerPage)
+	if err != nil {
+		return DORAReport{}, err
+	}
+
+	events := []boshdir.Event{}
+	err = reduceDeploymentsToCount(eventSource, []boshdir.Event{}, opts, itemsPerPage, func(newEvents []boshdir.Event) {
+		events = append(events, newEvents...)
+	})
+	if err != nil {
+		return DORAReport{}, err
+	}
+
+	return computeDORAMetrics(events, reportingPeriod.Duration(), repaveUser, deployment), nil
+}
+
+// computeDORAMetrics expects events newest first, as returned by the director.
+func computeDORAMetrics(events []boshdir.Event, periodDuration time.Duration, repaveUser string, deployment string) DORAReport {
+	uploads := []releaseUpload{}
+	accumulators := make(map[string]*doraAccumulator)
+
+	for i := len(events) - 1; i >= 0; i-- {
+		event := events[i]
+
+		if upload, ok := releaseUploadFromEvent(event); ok {
+			uploads = append(uploads, upload)
+			continue
+		}
+
+		if deployment != "" && event.DeploymentName() != deployment {
+			continue
+		}
+		if !IsNotRepaveUser(event, repaveUser) {
+			continue
+		}
+
+		if isDeployment(event) {
+			accumulator := accumulatorFor(accumulators, event.DeploymentName())
+			accumulator.successful += 1
+
+			for uploadIndex, upload := range uploads {
+				if !accumulator.deployedUploads[uploadIndex] && IsReleaseUpdate(event, upload.name, upload.version) {
+					accumulator.deployedUploads[uploadIndex] = true
+					accumulator.leadTimes = append(accumulator.leadTimes, event.Timestamp().Sub(upload.timestamp))
+				}
+			}
+
+			if !accumulator.failingSince.IsZero() {
+				accumulator.restoreTimes = append(accumulator.restoreTimes, event.Timestamp().Sub(accumulator.failingSince))
+				accumulator.failingSince = time.Time{}
+			}
+		} else if isFailedDeployment(event) {
+			accumulator := accumulatorFor(accumulators, event.DeploymentName())
+			accumulator.failed += 1
+
+			if accumulator.failingSince.IsZero() {
+				accumulator.failingSince = event.Timestamp()
+			}
+		}
+	}
+
+	report := DORAReport{ByDeployment: make(map[string]DORAMetrics)}
+	overall := &doraAccumulator{}
+
+	for deploymentName, accumulator := range accumulators {
+		report.ByDeployment[deploymentName] = accumulator.metrics(periodDuration)
+
+		overall.successful += accumulator.successful
+		overall.failed += accumulator.failed
+		overall.leadTimes = append(overall.leadTimes, accumulator.leadTimes...)
+		overall.restoreTimes = append(overall.restoreTimes, accumulator.restoreTimes...)
+	}
+	report.Overall = overall.metrics(periodDuration)
+
+	return report
+}
+
+func accumulatorFor(accumulators map[string]*doraAccumulator, deploymentName string) *doraAccumulator {
+	accumulator, ok := accumulators[deploymentName]
+	if !ok {
+		accumulator = &doraAccumulator{deployedUploads: make(map[int]bool)}
+		accumulators[deploymentName] = accumulator
+	}
+	return accumulator
+}
+
+func (a *doraAccumulator) metrics(periodDuration time.Duration) DORAMetrics {
+	metrics := DORAMetrics{
+		Deploys:           a.successful,
+		LeadTime:          SummarizeDurations(a.leadTimes).Median,
+		LeadTimeSamples:   len(a.leadTimes),
+		ChangeFailureRate: FailureRatio(a.successful, a.failed),
+		Restores:          len(a.restoreTimes),
+	}
+
+	if days := periodDuration.Hours() / 24; days > 0 {
+		metrics.DeploysPerDay = float64(a.successful) / days
+	}
+
+	if len(a.restoreTimes) > 0 {
+		var total time.Duration
+		for _, restoreTime := range a.restoreTimes {
+			total += restoreTime
+		}
+		metrics.TimeToRestore = total / time.Duration(len(a.restoreTimes))
+	}
+
+	return metrics
+}
+
+func releaseUploadFromEvent(event boshdir.Event) (releaseUpload, bool) {
+	if event.ObjectType() != "release" || event.Action() != "create" || event.Error() != "" {
+		return releaseUpload{}, false
+	}
+
+	name := event.ObjectName()
+	version, _ := event.Context()["version"].(string)
+
+	if nameComponents := strings.SplitN(name, "/", 2); len(nameComponents) == 2 {
+		name = nameComponents[0]
+		version = nameComponents[1]
+	}
+
+	if name == "" || version == "" {
+		return releaseUpload{}, false
+	}
+
+	return releaseUpload{name: name, version: version, timestamp: event.Timestamp()}, true
+}
+
+func (m DORAMetrics) MarshalJSON() ([]byte, error) {
+	return json.Marshal(map[string]interface{}{
+		"deploys":                      m.Deploys,
+		"deploys_per_day":              m.DeploysPerDay,
+		"lead_time_seconds":            m.LeadTime.Seconds(),
+		"lead_time_samples":            m.LeadTimeSamples,
+		"change_failure_rate":          m.ChangeFailureRate,
+		"mean_time_to_restore_seconds": m.TimeToRestore.Seconds(),
+		"restores":                     m.Restores,
+	})
+}
+
+func (r DORAReport) MarshalJSON() ([]byte, error) {
+	return json.Marshal(map[string]interface{}{
+		"overall":     r.Overall,
+		"deployments": r.ByDeployment,
+	})
+}
@@ -0,0 +1,101 @@
+package deployments_test
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("DORA metrics", func() {
+	events := `
+	[
+		{
+			"id": "6",
+			"action": "update",
+			"timestamp": 1447020000,
+			"user": "repave",
+			"object_type": "deployment",
+			"deployment": "cf",
+			"context": {"before": {"releases": ["cf/123"]}, "after": {"releases": ["cf/123"]}}
+		},
+		{
+			"id": "5",
+			"action": "update",
+			"timestamp": 1447010000,
+			"user": "admin",
+			"object_type": "deployment",
+			"deployment": "diego",
+			"context": {"before": {"releases": ["diego/1.6"]}, "after": {"releases": ["diego/1.6"]}}
+		},
+		{
+			"id": "4",
+			"action": "update",
+			"timestamp": 1447007200,
+			"user": "admin",
+			"object_type": "deployment",
+			"deployment": "cf",
+			"context": {"before": {"releases": ["cf/122"]}, "after": {"releases": ["cf/123"]}}
+		},
+		{
+			"id": "3",
+			"action": "update",
+			"timestamp": 1447005400,
+			"user": "admin",
+			"error": "still broken",
+			"object_type": "deployment",
+			"deployment": "cf"
+		},
+		{
+			"id": "2",
+			"action": "update",
+			"timestamp": 1447003600,
+			"user": "admin",
+			"error": "broken",
+			"object_type": "deployment",
+			"deployment": "cf"
+		},
+		{
+			"id": "1",
+			"action": "create",
+			"timestamp": 1447000000,
+			"user": "admin",
+			"object_type": "release",
+			"object_name": "cf",
+			"context": {"version": "123"}
+		}
+	]`
+
+	fake := serveDirector(serveEvents(events, "before_time=1448927999&after_time=1446336000"))
+
+	It("computes deploy frequency, lead time, change failure rate and time to restore per deployment", func() {
+		report, err := fake.deployCounter.DORAMetrics("2015/11", 999, "repave", "")
+		Expect(err).NotTo(HaveOccurred())
+
+		cf := report.ByDeployment["cf"]
+		Expect(cf.Deploys).To(Equal(1))
+		Expect(cf.DeploysPerDay).To(BeNumerically("~", 1.0/30, 0.0001))
+		Expect(cf.LeadTime).To(Equal(2 * time.Hour))
+		Expect(cf.LeadTimeSamples).To(Equal(1))
+		Expect(cf.ChangeFailureRate).To(BeNumerically("~", 2.0/3, 0.0001))
+		Expect(cf.TimeToRestore).To(Equal(1 * time.Hour))
+		Expect(cf.Restores).To(Equal(1))
+
+		diego := report.ByDeployment["diego"]
+		Expect(diego.Deploys).To(Equal(1))
+		Expect(diego.LeadTimeSamples).To(Equal(0))
+		Expect(diego.ChangeFailureRate).To(Equal(0.0))
+		Expect(diego.Restores).To(Equal(0))
+
+		Expect(report.Overall.Deploys).To(Equal(2))
+		Expect(report.Overall.ChangeFailureRate).To(Equal(0.5))
+		Expect(report.Overall.LeadTime).To(Equal(2 * time.Hour))
+	})
+
+	It("only reports on the given deployment", func() {
+		report, err := fake.deployCounter.DORAMetrics("2015/11", 999, "repave", "diego")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(report.ByDeployment).To(HaveLen(1))
+		Expect(report.ByDeployment).To(HaveKey("diego"))
+	})
+})
@@ -0,0 +1,142 @@
+package deployments
+
+import (
+	"encoding/json"
+	"time"
+
+	boshdir "github.com/cloudfoundry/bosh-cli/director"
+	boshlog "github.com/cloudfoundry/bosh-utils/logger"
+)
+
+type ErrandStats struct {
+	Runs       int
+	Successful int
+	Failed     int
+	PassRate   float64
+	Durations  DurationStats
+}
+
+type errandAccumulator struct {
+	successful int
+	failed     int
+	durations  []time.Duration
+}
+
+func (d *DeployCounter) ErrandRuns(period string, itemsPerPage int, deployment string) (map[string]map[string]ErrandStats, error) {
+	logger := boshlog.NewLogger(boshlog.LevelError)
+
+	reportingPeriod, err := d.reportingPeriod(period)
+	if err != nil {
+		return nil, err
+	}
+	opts := createCalendarOpts(reportingPeriod, deployment)
+
+	eventSource, err := createEventSource(d, logger, itemsPerPage)
+	if err != nil {
+		return nil, err
+	}
+
+	accumulators := make(map[string]map[string]*errandAccumulator)
+	pendingEndEvents := make(map[string]boshdir.Event)
+
+	err = reduceDeploymentsToCount(eventSource, []boshdir.Event{}, opts, itemsPerPage, func(events []boshdir.Event) {
+		errandEventCount(events, pendingEndEvents, accumulators)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	stats := make(map[string]map[string]ErrandStats)
+	for deploymentName, byErrand := range accumulators {
+		stats[deploymentName] = make(map[string]ErrandStats)
+		for errandName, accumulator := range byErrand {
+			stats[deploymentName][errandName] = accumulator.stats()
+		}
+	}
+	return stats, nil
+}
+
+// errandEventCount counts the end event of each errand run, which points to
+// its begin event and carries the outcome. Runs that began before the
+// reporting window are counted without a duration.
+func errandEventCount(events []boshdir.Event, pendingEndEvents map[string]boshdir.Event, accumulators map[string]map[string]*errandAccumulator) {
+	for _, event := range events {
+		if event.ObjectType() != "errand" || event.Action() != "run" {
+			continue
+		}
+
+		if endEvent, ok := pendingEndEvents[event.ID()]; ok {
+			accumulator := errandAccumulatorFor(accumulators, endEvent)
+			accumulator.durations = append(accumulator.durations, endEvent.Timestamp().Sub(event.Timestamp()))
+			delete(pendingEndEvents, event.ID())
+			continue
+		}
+
+		if event.ParentID() == "" {
+			continue
+		}
+
+		accumulator := errandAccumulatorFor(accumulators, event)
+		if isSuccessfulErrandRun(event) {
+			accumulator.successful += 1
+		} else {
+			accumulator.failed += 1
+		}
+		pendingEndEvents[event.ParentID()] = event
+	}
+}
+
+func errandAccumulatorFor(accumulators map[string]map[string]*errandAccumulator, event boshdir.Event) *errandAccumulator {
+	deploymentName := event.DeploymentName()
+	if accumulators[deploymentName] == nil {
+		accumulators[deploymentName] = make(map[string]*errandAccumulator)
+	}
+
+	accumulator, ok := accumulators[deploymentName][event.ObjectName()]
+	if !ok {
+		accumulator = &errandAccumulator{}
+		accumulators[deploymentName][event.ObjectName()] = accumulator
+	}
+	return accumulator
+}
+
+func isSuccessfulErrandRun(event boshdir.Event) bool {
+	if event.Error() != "" {
+		return false
+	}
+
+	switch exitCode := event.Context()["exit_code"].(type) {
+	case float64:
+		return exitCode == 0
+	case int:
+		return exitCode == 0
+	default:
+		return true
+	}
+}
+
+func (a *errandAccumulator) stats() ErrandStats {
+	runs := a.successful + a.failed
+	passRate := 0.0
+	if runs > 0 {
+		passRate = float64(a.successful) / float64(runs)
+	}
+
+	return ErrandStats{
+		Runs:       runs,
+		Successful: a.successful,
+		Failed:     a.failed,
+		PassRate:   passRate,
+		Durations:  SummarizeDurations(a.durations),
+	}
+}
+
+func (s ErrandStats) MarshalJSON() ([]byte, error) {
+	return json.Marshal(map[string]interface{}{
+		"runs":       s.Runs,
+		"successful": s.Successful,
+		"failed":     s.Failed,
+		"pass_rate":  s.PassRate,
+		"durations":  s.Durations,
+	})
+}
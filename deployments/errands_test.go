@@ -0,0 +1,50 @@
+package deployments_test
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Errand runs", func() {
+	events := `
+	[
+		{"id": "7", "parent_id": "6", "timestamp": 1447003100, "action": "run", "object_type": "errand", "object_name": "smoke-tests", "deployment": "cf", "context": {"exit_code": 1}},
+		{"id": "6", "timestamp": 1447003000, "action": "run", "object_type": "errand", "object_name": "smoke-tests", "deployment": "cf"},
+		{"id": "5", "parent_id": "4", "timestamp": 1447002300, "action": "run", "object_type": "errand", "object_name": "smoke-tests", "deployment": "cf", "context": {"exit_code": 0}},
+		{"id": "4", "timestamp": 1447002000, "action": "run", "object_type": "errand", "object_name": "smoke-tests", "deployment": "cf"},
+		{"id": "3", "parent_id": "2", "timestamp": 1447001000, "action": "run", "object_type": "errand", "object_name": "acceptance-tests", "deployment": "cf", "error": "timed out"},
+		{"id": "2", "timestamp": 1447000000, "action": "run", "object_type": "errand", "object_name": "acceptance-tests", "deployment": "cf"},
+		{"id": "1", "parent_id": "0", "timestamp": 1446336500, "action": "run", "object_type": "errand", "object_name": "smoke-tests", "deployment": "diego", "context": {"exit_code": 0}}
+	]`
+
+	fake := serveDirector(serveEvents(events, "before_time=1448927999&after_time=1446336000"))
+
+	It("counts successful and failed runs and their durations per deployment and errand", func() {
+		stats, err := fake.deployCounter.ErrandRuns("2015/11", 999, "")
+		Expect(err).NotTo(HaveOccurred())
+
+		smokeTests := stats["cf"]["smoke-tests"]
+		Expect(smokeTests.Runs).To(Equal(2))
+		Expect(smokeTests.Successful).To(Equal(1))
+		Expect(smokeTests.Failed).To(Equal(1))
+		Expect(smokeTests.PassRate).To(Equal(0.5))
+		Expect(smokeTests.Durations.Min).To(Equal(100 * time.Second))
+		Expect(smokeTests.Durations.Max).To(Equal(300 * time.Second))
+
+		acceptanceTests := stats["cf"]["acceptance-tests"]
+		Expect(acceptanceTests.Runs).To(Equal(1))
+		Expect(acceptanceTests.Failed).To(Equal(1))
+		Expect(acceptanceTests.Durations.Max).To(Equal(1000 * time.Second))
+	})
+
+	It("counts runs that began before the reporting period without a duration", func() {
+		stats, err := fake.deployCounter.ErrandRuns("2015/11", 999, "")
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(stats["diego"]["smoke-tests"].Runs).To(Equal(1))
+		Expect(stats["diego"]["smoke-tests"].PassRate).To(Equal(1.0))
+		Expect(stats["diego"]["smoke-tests"].Durations.Count).To(Equal(0))
+	})
+})
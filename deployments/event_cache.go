@@ -0,0 +1,248 @@
+package deployments
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"sync"
+
+	boshdir "github.com/cloudfoundry/bosh-cli/director"
+)
+
+// EventCache keeps every event fetched from a director in a JSON lines file,
+// one file per director. Events never change once recorded, so only events
+// newer than the newest cached one ever need to be fetched.
+type EventCache struct {
+	sync.Mutex
+	path   string
+	events []StoredEvent
+	ids    map[string]bool
+}
+
+var unsafeFileNameCharacters = regexp.MustCompile(`[^A-Za-z0-9.-]+`)
+
+var eventCaches = struct {
+	sync.Mutex
+	byPath map[string]*EventCache
+}{byPath: make(map[string]*EventCache)}
+
+func OpenEventCache(cacheDir string, directorURL string) (*EventCache, error) {
+	err := os.MkdirAll(cacheDir, 0700)
+	if err != nil {
+		return nil, err
+	}
+
+	cache := &EventCache{
+		path: eventCachePath(cacheDir, directorURL),
+		ids:  make(map[string]bool),
+	}
+
+	events, err := readEventsFile(cache.path)
+	if os.IsNotExist(err) {
+		return cache, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	cache.add(events)
+	return cache, nil
+}
+
+// cachedEventCache opens the cache of a director once, so long running
+// processes such as serve only read the file at start and then keep the
+// events in memory, fetching just the newer ones on each refresh.
+func cachedEventCache(cacheDir string, directorURL string) (*EventCache, error) {
+	eventCaches.Lock()
+	defer eventCaches.Unlock()
+
+	path := eventCachePath(cacheDir, directorURL)
+	if cache, ok := eventCaches.byPath[path]; ok {
+		return cache, nil
+	}
+
+	cache, err := OpenEventCache(cacheDir, directorURL)
+	if err != nil {
+		return nil, err
+	}
+	eventCaches.byPath[path] = cache
+	return cache, nil
+}
+
+func eventCachePath(cacheDir string, directorURL string) string {
+	return filepath.Join(cacheDir, fmt.Sprintf("events-%s.jsonl", unsafeFileNameCharacters.ReplaceAllString(directorURL, "_")))
+}
+
+func (c *EventCache) NewestEventID() string {
+	c.Lock()
+	defer c.Unlock()
+
+	return c.newestEventID()
+}
+
+func (c *EventCache) newestEventID() string {
+	if len(c.events) == 0 {
+		return ""
+	}
+	return c.events[0].ID()
+}
+
+func (c *EventCache) Sync(directorClient eventLister, itemsPerPage int) error {
+	c.Lock()
+	defer c.Unlock()
+
+	unseenEvents := []StoredEvent{}
+
+	err := reduceNewEvents(directorClient, []boshdir.Event{}, boshdir.EventsFilter{}, itemsPerPage, c.newestEventID(), func(events []boshdir.Event) {
+		for _, event := range events {
+			unseenEvents = append(unseenEvents, NewStoredEvent(event))
+		}
+	})
+	if err != nil {
+		return err
+	}
+
+	// Only write once every page has been fetched, so an interrupted sync
+	// cannot leave a gap behind the newest cached event.
+	err = appendEventsFile(c.path, unseenEvents)
+	if err != nil {
+		return err
+	}
+
+	c.add(unseenEvents)
+	return nil
+}
+
+func (c *EventCache) Events(opts boshdir.EventsFilter) ([]boshdir.Event, error) {
+	c.Lock()
+	defer c.Unlock()
+
+	return filterEvents(c.events, opts)
+}
+
+func (c *EventCache) add(events []StoredEvent) {
+	for _, event := range events {
+		if !c.ids[event.ID()] {
+			c.ids[event.ID()] = true
+			c.events = append(c.events, event)
+		}
+	}
+	sort.Sort(newestEventsFirst(c.events))
+}
+
+type newestEventsFirst []StoredEvent
+
+func (e newestEventsFirst) Len() int           { return len(e) }
+func (e newestEventsFirst) Less(i, j int) bool { return isNewerEventID(e[i].ID(), e[j].ID()) }
+func (e newestEventsFirst) Swap(i, j int)      { e[i], e[j] = e[j], e[i] }
+
+// filterEvents applies the director's /events filters to events that are
+// already sorted newest first.
+func filterEvents(events []StoredEvent, opts boshdir.EventsFilter) ([]boshdir.Event, error) {
+	var before, after int64
+	var err error
+
+	if opts.Before != "" {
+		before, err = strconv.ParseInt(opts.Before, 10, 64)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if opts.After != "" {
+		after, err = strconv.ParseInt(opts.After, 10, 64)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	filtered := []boshdir.Event{}
+	for _, event := range events {
+		if opts.BeforeID != "" && !isNewerEventID(opts.BeforeID, event.ID()) {
+			continue
+		}
+		if opts.Before != "" && event.EventTimestamp > before {
+			continue
+		}
+		if opts.After != "" && event.EventTimestamp < after {
+			continue
+		}
+		if opts.Deployment != "" && event.EventDeployment != opts.Deployment {
+			continue
+		}
+		if opts.Task != "" && event.EventTaskID != opts.Task {
+			continue
+		}
+		if opts.Instance != "" && event.EventInstance != opts.Instance {
+			continue
+		}
+		filtered = append(filtered, event)
+	}
+
+	return filtered, nil
+}
+
+func readEventsFile(path string) ([]StoredEvent, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	events, err := readEvents(file)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %s", path, err)
+	}
+	return events, nil
+}
+
+func readEvents(reader io.Reader) ([]StoredEvent, error) {
+	events := []StoredEvent{}
+	scanner := bufio.NewScanner(reader)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+
+	for lineNumber := 1; scanner.Scan(); lineNumber++ {
+		if len(bytes.TrimSpace(scanner.Bytes())) == 0 {
+			continue
+		}
+
+		var event StoredEvent
+		err := json.Unmarshal(scanner.Bytes(), &event)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %s", lineNumber, err)
+		}
+		events = append(events, event)
+	}
+
+	return events, scanner.Err()
+}
+
+func appendEventsFile(path string, events []StoredEvent) error {
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0600)
+	if err != nil {
+		return err
+	}
+
+	writer := bufio.NewWriter(file)
+	encoder := json.NewEncoder(writer)
+	for _, event := range events {
+		err := encoder.Encode(event)
+		if err != nil {
+			file.Close()
+			return err
+		}
+	}
+
+	err = writer.Flush()
+	if err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
@@ -0,0 +1,144 @@
+package deployments_test
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	boshdir "github.com/cloudfoundry/bosh-cli/director"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/pivotal-cloudops/bosh-stats/deployments"
+)
+
+type fakeEventLister struct {
+	pages    [][]deployments.StoredEvent
+	requests []boshdir.EventsFilter
+}
+
+func (f *fakeEventLister) Events(opts boshdir.EventsFilter) ([]boshdir.Event, error) {
+	f.requests = append(f.requests, opts)
+
+	events := []boshdir.Event{}
+	if len(f.pages) > 0 {
+		for _, event := range f.pages[0] {
+			events = append(events, event)
+		}
+		f.pages = f.pages[1:]
+	}
+	return events, nil
+}
+
+func storedEvents(jsonEvents string) []deployments.StoredEvent {
+	events := []deployments.StoredEvent{}
+	Expect(json.Unmarshal([]byte(jsonEvents), &events)).To(Succeed())
+	return events
+}
+
+var _ = Describe("EventCache", func() {
+	var cacheDir string
+
+	olderEvents := `
+	[
+		{"id": "3", "action": "update", "timestamp": 1448000300, "object_type": "deployment", "deployment": "cf", "context": {"before": {}, "after": {}}},
+		{"id": "2", "action": "update", "timestamp": 1448000200, "object_type": "deployment", "deployment": "diego", "context": {"before": {}, "after": {}}},
+		{"id": "1", "action": "update", "timestamp": 1440000000, "object_type": "deployment", "deployment": "cf", "context": {"before": {}, "after": {}}}
+	]`
+
+	newerEvents := `
+	[
+		{"id": "5", "action": "update", "timestamp": 1448000500, "object_type": "deployment", "deployment": "cf", "context": {"before": {}, "after": {}}},
+		{"id": "4", "action": "update", "timestamp": 1448000400, "error": "failed", "object_type": "deployment", "deployment": "cf"},
+		{"id": "3", "action": "update", "timestamp": 1448000300, "object_type": "deployment", "deployment": "cf", "context": {"before": {}, "after": {}}}
+	]`
+
+	fake := serveDirector()
+
+	BeforeEach(func() {
+		var err error
+		cacheDir, err = ioutil.TempDir("", "bosh-stats-cache")
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	AfterEach(func() {
+		os.RemoveAll(cacheDir)
+	})
+
+	eventIDs := func(events []boshdir.Event) []string {
+		ids := []string{}
+		for _, event := range events {
+			ids = append(ids, event.ID())
+		}
+		return ids
+	}
+
+	It("stores fetched events and only fetches newer events on later syncs", func() {
+		lister := &fakeEventLister{pages: [][]deployments.StoredEvent{storedEvents(olderEvents)}}
+
+		cache, err := deployments.OpenEventCache(cacheDir, fake.director.URL())
+		Expect(err).NotTo(HaveOccurred())
+		Expect(cache.NewestEventID()).To(Equal(""))
+
+		Expect(cache.Sync(lister, 3)).To(Succeed())
+		Expect(lister.requests).To(Equal([]boshdir.EventsFilter{{}, {BeforeID: "1"}}))
+		Expect(cache.NewestEventID()).To(Equal("3"))
+
+		reopenedCache, err := deployments.OpenEventCache(cacheDir, fake.director.URL())
+		Expect(err).NotTo(HaveOccurred())
+		Expect(reopenedCache.NewestEventID()).To(Equal("3"))
+
+		lister = &fakeEventLister{pages: [][]deployments.StoredEvent{storedEvents(newerEvents)}}
+		Expect(reopenedCache.Sync(lister, 3)).To(Succeed())
+		Expect(lister.requests).To(Equal([]boshdir.EventsFilter{{}}))
+
+		events, err := reopenedCache.Events(boshdir.EventsFilter{})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(eventIDs(events)).To(Equal([]string{"5", "4", "3", "2", "1"}))
+	})
+
+	It("filters cached events like the director does", func() {
+		lister := &fakeEventLister{pages: [][]deployments.StoredEvent{storedEvents(newerEvents)}}
+
+		cache, err := deployments.OpenEventCache(cacheDir, "https://10.0.0.6:25555")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(cache.Sync(lister, 200)).To(Succeed())
+
+		files, err := filepath.Glob(filepath.Join(cacheDir, "*"))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(files).To(Equal([]string{filepath.Join(cacheDir, "events-https_10.0.0.6_25555.jsonl")}))
+
+		events, err := cache.Events(boshdir.EventsFilter{BeforeID: "5", Before: "1448000400", After: "1448000300", Deployment: "cf"})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(eventIDs(events)).To(Equal([]string{"4", "3"}))
+	})
+
+	It("counts deploys from the cache when a cache dir is given", func() {
+		fake.director.AppendHandlers(serveEvents(olderEvents, ""))
+		fake.deployCounter.CacheDir = cacheDir
+
+		runningCount := make(map[string]int)
+		err := fake.deployCounter.SuccessfulDeploys("2015/11", 200, "repave", &runningCount, "")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(fake.director.ReceivedRequests()).To(HaveLen(1))
+		Expect(runningCount).To(Equal(map[string]int{"cf": 1, "diego": 1}))
+	})
+
+	It("keeps the cached events in memory between counts", func() {
+		fake.director.AppendHandlers(serveEvents(olderEvents, ""), serveEvents(newerEvents, ""))
+		fake.deployCounter.CacheDir = cacheDir
+
+		runningCount := make(map[string]int)
+		Expect(fake.deployCounter.SuccessfulDeploys("2015/11", 200, "repave", &runningCount, "")).To(Succeed())
+
+		files, err := filepath.Glob(filepath.Join(cacheDir, "*"))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(files).To(HaveLen(1))
+		Expect(ioutil.WriteFile(files[0], []byte("not json\n"), 0600)).To(Succeed())
+
+		runningCount = make(map[string]int)
+		Expect(fake.deployCounter.SuccessfulDeploys("2015/11", 200, "repave", &runningCount, "")).To(Succeed())
+		Expect(fake.director.ReceivedRequests()).To(HaveLen(2))
+		Expect(runningCount).To(Equal(map[string]int{"cf": 2, "diego": 1}))
+	})
+})
@@ -0,0 +1,194 @@
+package deployments
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"sort"
+	"strings"
+	"time"
+
+	boshdir "github.com/cloudfoundry/bosh-cli/director"
+	boshlog "github.com/cloudfoundry/bosh-utils/logger"
+	"gopkg.in/yaml.v2"
+)
+
+// EventsFile serves events read from a file, either JSON lines as written by
+// DumpEvents or the output of `bosh events --json`.
+type EventsFile struct {
+	events []StoredEvent
+}
+
+type boshCLIEventsOutput struct {
+	Tables []struct {
+		Rows []boshCLIEventRow
+	}
+}
+
+type boshCLIEventRow struct {
+	ID         string      `json:"id"`
+	Time       string      `json:"time"`
+	User       string      `json:"user"`
+	Action     string      `json:"action"`
+	ObjectType string      `json:"object_type"`
+	ObjectName string      `json:"object_name"`
+	TaskID     string      `json:"task_id"`
+	Deployment string      `json:"deployment"`
+	Instance   string      `json:"instance"`
+	Context    interface{} `json:"context"`
+	Error      string      `json:"error"`
+}
+
+func OpenEventsFile(path string) (*EventsFile, error) {
+	contents, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var events []StoredEvent
+
+	var cliOutput boshCLIEventsOutput
+	if json.Unmarshal(contents, &cliOutput) == nil && cliOutput.Tables != nil {
+		events, err = eventsFromBoshCLIOutput(cliOutput)
+	} else {
+		events, err = readEvents(bytes.NewReader(contents))
+	}
+	if err != nil {
+		return nil, fmt.Errorf("reading events from %s: %s", path, err)
+	}
+
+	sort.Sort(newestEventsFirst(events))
+	return &EventsFile{events: events}, nil
+}
+
+func (f *EventsFile) Events(opts boshdir.EventsFilter) ([]boshdir.Event, error) {
+	return filterEvents(f.events, opts)
+}
+
+func (d *DeployCounter) DumpEvents(period string, itemsPerPage int, deployment string, w io.Writer) error {
+	logger := boshlog.NewLogger(boshlog.LevelError)
+
+	opts := boshdir.EventsFilter{Deployment: deployment}
+	if period != "" {
+		reportingPeriod, err := d.reportingPeriod(period)
+		if err != nil {
+			return err
+		}
+		opts = createCalendarOpts(reportingPeriod, deployment)
+	}
+
+	eventSource, err := createEventSource(d, logger, itemsPerPage)
+	if err != nil {
+		return err
+	}
+
+	var writeErr error
+	err = reduceDeploymentsToCount(eventSource, []boshdir.Event{}, opts, itemsPerPage, func(events []boshdir.Event) {
+		if writeErr == nil {
+			writeErr = WriteEvents(w, events)
+		}
+	})
+	if err != nil {
+		return err
+	}
+
+	return writeErr
+}
+
+func WriteEvents(w io.Writer, events []boshdir.Event) error {
+	encoder := json.NewEncoder(w)
+	for _, event := range events {
+		err := encoder.Encode(NewStoredEvent(event))
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func eventsFromBoshCLIOutput(output boshCLIEventsOutput) ([]StoredEvent, error) {
+	events := []StoredEvent{}
+
+	for _, table := range output.Tables {
+		for _, row := range table.Rows {
+			timestamp, err := time.Parse(time.UnixDate, row.Time)
+			if err != nil {
+				return nil, err
+			}
+
+			context, err := parseEventContext(row.Context)
+			if err != nil {
+				return nil, fmt.Errorf("event %s context: %s", row.ID, err)
+			}
+
+			// The CLI shows end events as "<id> <- <parent id>".
+			ids := strings.SplitN(row.ID, "<-", 2)
+			event := StoredEvent{
+				EventID:         strings.TrimSpace(ids[0]),
+				EventTimestamp:  timestamp.Unix(),
+				EventUser:       row.User,
+				EventAction:     row.Action,
+				EventObjectType: row.ObjectType,
+				EventObjectName: row.ObjectName,
+				EventTaskID:     row.TaskID,
+				EventDeployment: row.Deployment,
+				EventInstance:   row.Instance,
+				EventContext:    context,
+				EventError:      row.Error,
+			}
+			if len(ids) == 2 {
+				event.EventParentID = strings.TrimSpace(ids[1])
+			}
+
+			events = append(events, event)
+		}
+	}
+
+	return events, nil
+}
+
+// parseEventContext accepts the context either as a JSON object or as the
+// YAML text the CLI renders it as.
+func parseEventContext(context interface{}) (map[string]interface{}, error) {
+	switch value := context.(type) {
+	case nil:
+		return map[string]interface{}{}, nil
+	case map[string]interface{}:
+		return value, nil
+	case string:
+		var parsed interface{}
+		err := yaml.Unmarshal([]byte(value), &parsed)
+		if err != nil {
+			return nil, err
+		}
+
+		contextMap, ok := stringKeyedValue(parsed).(map[string]interface{})
+		if !ok {
+			return map[string]interface{}{}, nil
+		}
+		return contextMap, nil
+	default:
+		return nil, fmt.Errorf("unexpected context %v", context)
+	}
+}
+
+func stringKeyedValue(value interface{}) interface{} {
+	switch typedValue := value.(type) {
+	case map[interface{}]interface{}:
+		converted := make(map[string]interface{})
+		for key, nestedValue := range typedValue {
+			converted[fmt.Sprintf("%v", key)] = stringKeyedValue(nestedValue)
+		}
+		return converted
+	case []interface{}:
+		converted := make([]interface{}, len(typedValue))
+		for i, nestedValue := range typedValue {
+			converted[i] = stringKeyedValue(nestedValue)
+		}
+		return converted
+	default:
+		return value
+	}
+}
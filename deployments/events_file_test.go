@@ -0,0 +1,137 @@
+package deployments_test
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	boshdir "github.com/cloudfoundry/bosh-cli/director"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/pivotal-cloudops/bosh-stats/deployments"
+)
+
+var _ = Describe("reading events from a file", func() {
+	var tempDir string
+
+	jsonLinesEvents := `{"id":"3","parent_id":"2","timestamp":1448000600,"user":"admin","action":"update","object_type":"deployment","object_name":"cf","task":"9","deployment":"cf","instance":"","context":{"before":{"releases":["cf/122"]},"after":{"releases":["cf/123"]}},"error":""}
+{"id":"2","timestamp":1448000000,"user":"admin","action":"update","object_type":"deployment","object_name":"cf","task":"9","deployment":"cf","instance":"","context":{},"error":""}
+
+{"id":"1","timestamp":1448000000,"user":"admin","action":"update","object_type":"deployment","object_name":"diego","task":"8","deployment":"diego","instance":"","context":{"before":{},"after":{}},"error":"failed"}
+`
+
+	boshCLIEvents := `{
+		"Tables": [
+			{
+				"Content": "events",
+				"Rows": [
+					{
+						"action": "update",
+						"context": "after:\n  releases:\n  - cf/123\n  stemcells:\n  - bosh-aws-xen-hvm-ubuntu-trusty-go_agent/3312.12\nbefore:\n  releases:\n  - cf/122\n  stemcells:\n  - bosh-aws-xen-hvm-ubuntu-trusty-go_agent/3312.12",
+						"deployment": "cf",
+						"error": "",
+						"id": "3 <- 2",
+						"instance": "",
+						"object_name": "cf",
+						"object_type": "deployment",
+						"task_id": "9",
+						"time": "Fri Nov 20 06:23:20 UTC 2015",
+						"user": "admin"
+					},
+					{
+						"action": "update",
+						"context": "",
+						"deployment": "cf",
+						"error": "",
+						"id": "2",
+						"instance": "",
+						"object_name": "cf",
+						"object_type": "deployment",
+						"task_id": "9",
+						"time": "Fri Nov 20 06:13:20 UTC 2015",
+						"user": "admin"
+					}
+				],
+				"Notes": null
+			}
+		],
+		"Blocks": null,
+		"Lines": ["Using environment '10.0.0.6' as client 'admin'", "Succeeded"]
+	}`
+
+	BeforeEach(func() {
+		var err error
+		tempDir, err = ioutil.TempDir("", "bosh-stats-events-file")
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	AfterEach(func() {
+		os.RemoveAll(tempDir)
+	})
+
+	writeEventsFile := func(contents string) string {
+		path := filepath.Join(tempDir, "events.json")
+		Expect(ioutil.WriteFile(path, []byte(contents), 0600)).To(Succeed())
+		return path
+	}
+
+	It("computes reports from JSON lines without connecting to a director", func() {
+		deployCounter := &deployments.DeployCounter{EventsFile: writeEventsFile(jsonLinesEvents)}
+
+		successful := make(map[string]int)
+		Expect(deployCounter.SuccessfulDeploys("2015/11", 200, "repave", &successful, "")).To(Succeed())
+		Expect(successful).To(Equal(map[string]int{"cf": 1}))
+
+		failed := make(map[string]int)
+		Expect(deployCounter.FailedDeploys("2015/11", 200, "repave", &failed, "")).To(Succeed())
+		Expect(failed).To(Equal(map[string]int{"diego": 1}))
+
+		durations := make(map[string][]time.Duration)
+		Expect(deployCounter.DeployDurations("2015/11", 200, "repave", &durations, "")).To(Succeed())
+		Expect(durations).To(Equal(map[string][]time.Duration{"cf": {10 * time.Minute}}))
+	})
+
+	It("reads the output of bosh events --json", func() {
+		eventsFile, err := deployments.OpenEventsFile(writeEventsFile(boshCLIEvents))
+		Expect(err).NotTo(HaveOccurred())
+
+		events, err := eventsFile.Events(boshdir.EventsFilter{})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(events).To(HaveLen(2))
+
+		Expect(events[0].ID()).To(Equal("3"))
+		Expect(events[0].ParentID()).To(Equal("2"))
+		Expect(events[0].Timestamp()).To(Equal(time.Unix(1448000600, 0).UTC()))
+		Expect(events[0].TaskID()).To(Equal("9"))
+		Expect(deployments.IsReleaseUpdate(events[0], "cf", "123")).To(BeTrue())
+
+		Expect(events[1].ID()).To(Equal("2"))
+		Expect(events[1].Context()).To(BeEmpty())
+
+		deployCounter := &deployments.DeployCounter{EventsFile: writeEventsFile(boshCLIEvents)}
+		date, err := deployCounter.DeployDate("cf", "123", 200)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(date).To(Equal(time.Unix(1448000600, 0).UTC()))
+	})
+
+	It("dumps events as JSON lines that can be read back", func() {
+		deployCounter := &deployments.DeployCounter{EventsFile: writeEventsFile(boshCLIEvents)}
+
+		dump := &bytes.Buffer{}
+		Expect(deployCounter.DumpEvents("", 200, "", dump)).To(Succeed())
+		Expect(strings.Count(dump.String(), "\n")).To(Equal(2))
+
+		roundTripCounter := &deployments.DeployCounter{EventsFile: writeEventsFile(dump.String())}
+		successful := make(map[string]int)
+		Expect(roundTripCounter.SuccessfulDeploys("2015/11", 200, "", &successful, "")).To(Succeed())
+		Expect(successful).To(Equal(map[string]int{"cf": 1}))
+	})
+
+	It("returns an error for malformed files", func() {
+		_, err := deployments.OpenEventsFile(writeEventsFile("{\"id\": \"1\"}\nnot json\n"))
+		Expect(err).To(MatchError(ContainSubstring("line 2")))
+	})
+})
@@ -0,0 +1,72 @@
+package deployments
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"sync"
+)
+
+type DirectorTarget struct {
+	Name       string `json:"name"`
+	CaCertPath string `json:"ca_cert_path"`
+	DeployCounter
+}
+
+type DirectorDeploys struct {
+	Director string
+	Deploys  map[string]int
+	Err      error
+}
+
+func LoadDirectorTargets(path string) ([]DirectorTarget, error) {
+	contents, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var targets []DirectorTarget
+	err = json.Unmarshal(contents, &targets)
+	if err != nil {
+		return nil, fmt.Errorf("parsing director targets %s: %s", path, err)
+	}
+
+	for i, target := range targets {
+		if target.Name == "" {
+			targets[i].Name = target.DirectorURL
+		}
+
+		if target.CaCertPath != "" {
+			caCert, err := ioutil.ReadFile(target.CaCertPath)
+			if err != nil {
+				return nil, fmt.Errorf("reading CA cert for director %s: %s", targets[i].Name, err)
+			}
+			targets[i].CaCert = string(caCert)
+		}
+	}
+
+	return targets, nil
+}
+
+func FleetSuccessfulDeploys(targets []DirectorTarget, period string, itemsPerPage int, repaveUser string, deployment string) []DirectorDeploys {
+	results := make([]DirectorDeploys, len(targets))
+
+	var wg sync.WaitGroup
+	for i := range targets {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+
+			runningCount := make(map[string]int)
+			err := targets[i].SuccessfulDeploys(period, itemsPerPage, repaveUser, &runningCount, deployment)
+			results[i] = DirectorDeploys{
+				Director: targets[i].Name,
+				Deploys:  runningCount,
+				Err:      err,
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	return results
+}
@@ -0,0 +1,110 @@
+package deployments_test
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/pivotal-cloudops/bosh-stats/deployments"
+)
+
+var _ = Describe("fleet of directors", func() {
+	var tempDir string
+
+	BeforeEach(func() {
+		var err error
+		tempDir, err = ioutil.TempDir("", "bosh-stats-fleet")
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	AfterEach(func() {
+		os.RemoveAll(tempDir)
+	})
+
+	Describe("LoadDirectorTargets", func() {
+		It("loads targets with their own credentials and CA certs", func() {
+			caCertPath := filepath.Join(tempDir, "ca.pem")
+			Expect(ioutil.WriteFile(caCertPath, []byte(validCACert), 0600)).To(Succeed())
+
+			targetsPath := filepath.Join(tempDir, "targets.json")
+			Expect(ioutil.WriteFile(targetsPath, []byte(fmt.Sprintf(`[
+				{
+					"name": "prod",
+					"director_url": "https://10.0.0.6:25555",
+					"uaa_url": "https://10.0.0.6:8443",
+					"uaa_client_id": "bosh-stats",
+					"uaa_client_secret": "secret",
+					"ca_cert_path": %q
+				},
+				{
+					"director_url": "https://10.1.0.6:25555",
+					"ca_cert": "some-ca"
+				}
+			]`, caCertPath)), 0600)).To(Succeed())
+
+			targets, err := deployments.LoadDirectorTargets(targetsPath)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(targets).To(HaveLen(2))
+
+			Expect(targets[0].Name).To(Equal("prod"))
+			Expect(targets[0].UaaClientID).To(Equal("bosh-stats"))
+			Expect(targets[0].UaaClientSecret).To(Equal("secret"))
+			Expect(targets[0].CaCert).To(Equal(validCACert))
+
+			Expect(targets[1].Name).To(Equal("https://10.1.0.6:25555"))
+			Expect(targets[1].CaCert).To(Equal("some-ca"))
+		})
+
+		It("returns an error for malformed targets", func() {
+			targetsPath := filepath.Join(tempDir, "targets.json")
+			Expect(ioutil.WriteFile(targetsPath, []byte(`{"name": "not-a-list"}`), 0600)).To(Succeed())
+
+			_, err := deployments.LoadDirectorTargets(targetsPath)
+			Expect(err).To(HaveOccurred())
+		})
+	})
+
+	Describe("FleetSuccessfulDeploys", func() {
+		events := `
+		[
+			{
+				"id": "1",
+				"action": "update",
+				"object_type": "deployment",
+				"deployment": "cf",
+				"context": {"before": {}, "after": {}}
+			}
+		]`
+
+		fake := serveDirector(serveEvents(events, "before_time=1448927999&after_time=1446336000"))
+
+		It("collects from every director and reports unreachable ones as errors", func() {
+			targets := []deployments.DirectorTarget{
+				{
+					Name:          "reachable",
+					DeployCounter: *fake.deployCounter,
+				},
+				{
+					Name: "unreachable",
+					DeployCounter: deployments.DeployCounter{
+						DirectorURL: "",
+						UaaURL:      fake.uaa.URL(),
+					},
+				},
+			}
+
+			results := deployments.FleetSuccessfulDeploys(targets, "2015/11", 999, "repave", "")
+			Expect(results).To(HaveLen(2))
+
+			Expect(results[0].Director).To(Equal("reachable"))
+			Expect(results[0].Err).NotTo(HaveOccurred())
+			Expect(results[0].Deploys).To(Equal(map[string]int{"cf": 1}))
+
+			Expect(results[1].Director).To(Equal("unreachable"))
+			Expect(results[1].Err).To(HaveOccurred())
+		})
+	})
+})
@@ -0,0 +1,99 @@
+package deployments
+
+import (
+	"encoding/json"
+	"time"
+
+	boshdir "github.com/cloudfoundry/bosh-cli/director"
+	boshlog "github.com/cloudfoundry/bosh-utils/logger"
+)
+
+// HeatmapWeekdays orders the heatmap rows, starting the week on Monday.
+var HeatmapWeekdays = []time.Weekday{
+	time.Monday,
+	time.Tuesday,
+	time.Wednesday,
+	time.Thursday,
+	time.Friday,
+	time.Saturday,
+	time.Sunday,
+}
+
+type Heatmap struct {
+	Location *time.Location
+	Counts   [7][24]int
+}
+
+func (d *DeployCounter) DeployHeatmap(period string, itemsPerPage int, repaveUser string, deployment string) (Heatmap, error) {
+	if _, err := cachedUserMatcher(repaveUser); err != nil {
+		return Heatmap{}, err
+	}
+
+	logger := boshlog.NewLogger(boshlog.LevelError)
+
+	location := d.Timezone
+	if location == nil {
+		location = time.UTC
+	}
+	heatmap := Heatmap{Location: location}
+
+	reportingPeriod, err := d.reportingPeriod(period)
+	if err != nil {
+		return heatmap, err
+	}
+	opts := createCalendarOpts(reportingPeriod, deployment)
+
+	eventSource, err := createEventSource(d, logger, itemsPerPage)
+	if err != nil {
+		return heatmap, err
+	}
+
+	err = reduceDeploymentsToCount(eventSource, []boshdir.Event{}, opts, itemsPerPage, func(events []boshdir.Event) {
+		for _, event := range events {
+			if isDeployment(event) && IsNotRepaveUser(event, repaveUser) {
+				heatmap.Add(event.Timestamp())
+			}
+		}
+	})
+	if err != nil {
+		return heatmap, err
+	}
+
+	return heatmap, nil
+}
+
+func (h *Heatmap) Add(timestamp time.Time) {
+	local := timestamp.In(h.Location)
+	h.Counts[local.Weekday()][local.Hour()] += 1
+}
+
+func (h Heatmap) Count(weekday time.Weekday, hour int) int {
+	return h.Counts[weekday][hour]
+}
+
+func (h Heatmap) Max() int {
+	max := 0
+	for _, hours := range h.Counts {
+		for _, count := range hours {
+			if count > max {
+				max = count
+			}
+		}
+	}
+	return max
+}
+
+func (h Heatmap) MarshalJSON() ([]byte, error) {
+	weekdays := []string{}
+	counts := [][]int{}
+	for _, weekday := range HeatmapWeekdays {
+		weekdays = append(weekdays, weekday.String())
+		counts = append(counts, h.Counts[weekday][:])
+	}
+
+	return json.Marshal(map[string]interface{}{
+		"timezone": h.Location.String(),
+		"weekdays": weekdays,
+		"counts":   counts,
+	})
+}
@@ -0,0 +1,61 @@
+package deployments_test
+
+import (
+	"encoding/json"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Deploy heatmap", func() {
+	// 1447434000 is Friday 2015-11-13 17:00 UTC, 12:00 in New York.
+	events := `
+	[
+		{"id": "4", "timestamp": 1447434000, "user": "admin", "action": "update", "object_type": "deployment", "deployment": "cf", "context": {"before": {}, "after": {}}},
+		{"id": "3", "timestamp": 1447435800, "user": "admin", "action": "update", "object_type": "deployment", "deployment": "cf", "context": {"before": {}, "after": {}}},
+		{"id": "2", "timestamp": 1447434000, "user": "repave", "action": "update", "object_type": "deployment", "deployment": "cf", "context": {"before": {}, "after": {}}},
+		{"id": "1", "timestamp": 1447434000, "user": "admin", "action": "update", "error": "failed", "object_type": "deployment", "deployment": "cf"}
+	]`
+
+	fake := serveDirector(serveEvents(events))
+
+	It("buckets successful deploys by weekday and hour, skipping the repave user", func() {
+		heatmap, err := fake.deployCounter.DeployHeatmap("2015/11", 999, "repave", "")
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(heatmap.Count(time.Friday, 17)).To(Equal(2))
+		Expect(heatmap.Max()).To(Equal(2))
+	})
+
+	It("buckets in the configured timezone", func() {
+		location, err := time.LoadLocation("America/New_York")
+		Expect(err).NotTo(HaveOccurred())
+		fake.deployCounter.Timezone = location
+
+		heatmap, err := fake.deployCounter.DeployHeatmap("2015/11", 999, "repave", "")
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(heatmap.Count(time.Friday, 12)).To(Equal(2))
+		Expect(heatmap.Count(time.Friday, 17)).To(Equal(0))
+	})
+
+	It("marshals to a matrix of weekdays starting on Monday", func() {
+		heatmap, err := fake.deployCounter.DeployHeatmap("2015/11", 999, "repave", "")
+		Expect(err).NotTo(HaveOccurred())
+
+		jsonOutput, err := json.Marshal(heatmap)
+		Expect(err).NotTo(HaveOccurred())
+
+		var matrix struct {
+			Timezone string   `json:"timezone"`
+			Weekdays []string `json:"weekdays"`
+			Counts   [][]int  `json:"counts"`
+		}
+		Expect(json.Unmarshal(jsonOutput, &matrix)).To(Succeed())
+		Expect(matrix.Timezone).To(Equal("UTC"))
+		Expect(matrix.Weekdays[0]).To(Equal("Monday"))
+		Expect(matrix.Counts).To(HaveLen(7))
+		Expect(matrix.Counts[4][17]).To(Equal(2))
+	})
+})
@@ -0,0 +1,167 @@
+package deployments
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type Period struct {
+	Start time.Time
+	End   time.Time
+}
+
+var (
+	monthPeriodPattern    = regexp.MustCompile(`^(\d{4})[/-](\d{1,2})$`)
+	weekPeriodPattern     = regexp.MustCompile(`^(\d{4})-W(\d{1,2})$`)
+	quarterPeriodPattern  = regexp.MustCompile(`^(\d{4})-Q([1-4])$`)
+	relativePeriodPattern = regexp.MustCompile(`^last\s*(\d+)\s*([hdw])$`)
+)
+
+var timestampLayouts = []string{
+	time.RFC3339,
+	"2006-01-02T15:04:05",
+	"2006-01-02T15:04",
+}
+
+// ParsePeriod accepts YYYY/MM, ISO weeks (YYYY-Www), quarters (YYYY-Qn),
+// relative ranges ("last 30d", "last 2w", "last 12h") and explicit FROM..TO
+// ranges of months, dates or timestamps. Calendar boundaries are taken in location.
+func ParsePeriod(spec string, location *time.Location, now time.Time) (Period, error) {
+	if location == nil {
+		location = time.UTC
+	}
+	spec = strings.TrimSpace(spec)
+
+	if matches := monthPeriodPattern.FindStringSubmatch(spec); matches != nil {
+		year, _ := strconv.Atoi(matches[1])
+		month, _ := strconv.Atoi(matches[2])
+		if month < 1 || month > 12 {
+			return Period{}, fmt.Errorf("invalid period %q: month must be between 1 and 12", spec)
+		}
+
+		start := time.Date(year, time.Month(month), 1, 0, 0, 0, 0, location)
+		return newPeriod(start, start.AddDate(0, 1, 0)), nil
+	}
+
+	if matches := weekPeriodPattern.FindStringSubmatch(spec); matches != nil {
+		year, _ := strconv.Atoi(matches[1])
+		week, _ := strconv.Atoi(matches[2])
+
+		// ISO week 1 is the week containing January 4th.
+		january4 := time.Date(year, time.January, 4, 0, 0, 0, 0, location)
+		week1Monday := january4.AddDate(0, 0, -((int(january4.Weekday()) + 6) % 7))
+		start := week1Monday.AddDate(0, 0, (week-1)*7)
+
+		if isoYear, isoWeek := start.ISOWeek(); week < 1 || isoYear != year || isoWeek != week {
+			return Period{}, fmt.Errorf("invalid period %q: %d has no ISO week %d", spec, year, week)
+		}
+		return newPeriod(start, start.AddDate(0, 0, 7)), nil
+	}
+
+	if matches := quarterPeriodPattern.FindStringSubmatch(spec); matches != nil {
+		year, _ := strconv.Atoi(matches[1])
+		quarter, _ := strconv.Atoi(matches[2])
+
+		start := time.Date(year, time.Month((quarter-1)*3+1), 1, 0, 0, 0, 0, location)
+		return newPeriod(start, start.AddDate(0, 3, 0)), nil
+	}
+
+	if matches := relativePeriodPattern.FindStringSubmatch(spec); matches != nil {
+		count, _ := strconv.Atoi(matches[1])
+		if count == 0 {
+			return Period{}, fmt.Errorf("invalid period %q: range must not be empty", spec)
+		}
+
+		unit := map[string]time.Duration{"h": time.Hour, "d": 24 * time.Hour, "w": 7 * 24 * time.Hour}[matches[2]]
+		end := now.In(location)
+		return Period{Start: end.Add(-time.Duration(count) * unit), End: end}, nil
+	}
+
+	if rangeComponents := strings.SplitN(spec, "..", 2); len(rangeComponents) == 2 {
+		return parseRangePeriod(spec, rangeComponents[0], rangeComponents[1], location, now)
+	}
+
+	return Period{}, fmt.Errorf("invalid period %q: expected YYYY/MM, YYYY-Www, YYYY-Qn, 'last <n>d' or <from>..<to>", spec)
+}
+
+func parseRangePeriod(spec string, from string, to string, location *time.Location, now time.Time) (Period, error) {
+	if from == "" {
+		return Period{}, fmt.Errorf("invalid period %q: missing start of range", spec)
+	}
+
+	start, _, err := parsePeriodBoundary(from, location)
+	if err != nil {
+		return Period{}, fmt.Errorf("invalid period %q: %s", spec, err)
+	}
+
+	end := now.In(location)
+	if to != "" {
+		// A date or month on its own includes the whole of that day or month.
+		_, end, err = parsePeriodBoundary(to, location)
+		if err != nil {
+			return Period{}, fmt.Errorf("invalid period %q: %s", spec, err)
+		}
+	}
+
+	if end.Before(start) {
+		return Period{}, fmt.Errorf("invalid period %q: end is before start", spec)
+	}
+
+	return Period{Start: start, End: end}, nil
+}
+
+// parsePeriodBoundary returns the first and last instant value covers, which
+// are the same instant for a timestamp.
+func parsePeriodBoundary(value string, location *time.Location) (time.Time, time.Time, error) {
+	value = strings.TrimSpace(value)
+
+	if matches := monthPeriodPattern.FindStringSubmatch(value); matches != nil {
+		year, _ := strconv.Atoi(matches[1])
+		month, _ := strconv.Atoi(matches[2])
+		if month < 1 || month > 12 {
+			return time.Time{}, time.Time{}, fmt.Errorf("cannot parse %q: month must be between 1 and 12", value)
+		}
+
+		start := time.Date(year, time.Month(month), 1, 0, 0, 0, 0, location)
+		return start, start.AddDate(0, 1, 0).Add(-time.Nanosecond), nil
+	}
+
+	if date, err := time.ParseInLocation("2006-01-02", value, location); err == nil {
+		return date, date.AddDate(0, 0, 1).Add(-time.Nanosecond), nil
+	}
+
+	for _, layout := range timestampLayouts {
+		if timestamp, err := time.ParseInLocation(layout, value, location); err == nil {
+			return timestamp, timestamp, nil
+		}
+	}
+
+	return time.Time{}, time.Time{}, fmt.Errorf("cannot parse %q as YYYY/MM, YYYY-MM-DD or an RFC3339 timestamp", value)
+}
+
+func newPeriod(start time.Time, nextStart time.Time) Period {
+	return Period{Start: start, End: nextStart.Add(-time.Nanosecond)}
+}
+
+func (p Period) Duration() time.Duration {
+	return p.End.Sub(p.Start)
+}
+
+func (p Period) Label() string {
+	if p.Start.Day() == 1 && isMidnight(p.Start) && p.End.Add(time.Nanosecond).Equal(p.Start.AddDate(0, 1, 0)) {
+		return p.Start.Format("Jan 2006")
+	}
+
+	if isMidnight(p.Start) && isMidnight(p.End.Add(time.Nanosecond)) {
+		return fmt.Sprintf("%s - %s", p.Start.Format("2006-01-02"), p.End.Format("2006-01-02"))
+	}
+
+	return fmt.Sprintf("%s - %s", p.Start.Format("2006-01-02 15:04"), p.End.Format("2006-01-02 15:04"))
+}
+
+func isMidnight(t time.Time) bool {
+	return t.Hour() == 0 && t.Minute() == 0 && t.Second() == 0 && t.Nanosecond() == 0
+}
@@ -0,0 +1,87 @@
+package deployments_test
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/pivotal-cloudops/bosh-stats/deployments"
+)
+
+var _ = Describe("ParsePeriod", func() {
+	now := time.Date(2017, time.March, 15, 12, 30, 0, 0, time.UTC)
+
+	It("parses a calendar month", func() {
+		period, err := deployments.ParsePeriod("2015/11", time.UTC, now)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(period.Start.Unix()).To(Equal(int64(1446336000)))
+		Expect(period.End.Unix()).To(Equal(int64(1448927999)))
+		Expect(period.Label()).To(Equal("Nov 2015"))
+	})
+
+	It("uses month boundaries in the given timezone", func() {
+		newYork, err := time.LoadLocation("America/New_York")
+		Expect(err).NotTo(HaveOccurred())
+
+		period, err := deployments.ParsePeriod("2017/01", newYork, now)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(period.Start).To(BeTemporally("==", time.Date(2017, time.January, 1, 5, 0, 0, 0, time.UTC)))
+		Expect(period.End).To(BeTemporally("==", time.Date(2017, time.February, 1, 5, 0, 0, 0, time.UTC).Add(-time.Nanosecond)))
+	})
+
+	It("parses an ISO week", func() {
+		period, err := deployments.ParsePeriod("2016-W01", time.UTC, now)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(period.Start).To(Equal(time.Date(2016, time.January, 4, 0, 0, 0, 0, time.UTC)))
+		Expect(period.End).To(Equal(time.Date(2016, time.January, 11, 0, 0, 0, 0, time.UTC).Add(-time.Nanosecond)))
+		Expect(period.Label()).To(Equal("2016-01-04 - 2016-01-10"))
+	})
+
+	It("rejects ISO weeks the year does not have", func() {
+		_, err := deployments.ParsePeriod("2017-W53", time.UTC, now)
+		Expect(err).To(MatchError(`invalid period "2017-W53": 2017 has no ISO week 53`))
+	})
+
+	It("parses a quarter", func() {
+		period, err := deployments.ParsePeriod("2017-Q2", time.UTC, now)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(period.Start).To(Equal(time.Date(2017, time.April, 1, 0, 0, 0, 0, time.UTC)))
+		Expect(period.End).To(Equal(time.Date(2017, time.July, 1, 0, 0, 0, 0, time.UTC).Add(-time.Nanosecond)))
+	})
+
+	It("parses a relative range ending now", func() {
+		period, err := deployments.ParsePeriod("last 30d", time.UTC, now)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(period.Start).To(Equal(now.AddDate(0, 0, -30)))
+		Expect(period.End).To(Equal(now))
+	})
+
+	It("parses a range of dates including the whole last day", func() {
+		period, err := deployments.ParsePeriod("2017-01-10..2017-01-20", time.UTC, now)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(period.Start).To(Equal(time.Date(2017, time.January, 10, 0, 0, 0, 0, time.UTC)))
+		Expect(period.End).To(Equal(time.Date(2017, time.January, 21, 0, 0, 0, 0, time.UTC).Add(-time.Nanosecond)))
+	})
+
+	It("parses a range of months including the whole last month", func() {
+		period, err := deployments.ParsePeriod("2017/01..2017/03", time.UTC, now)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(period.Start).To(Equal(time.Date(2017, time.January, 1, 0, 0, 0, 0, time.UTC)))
+		Expect(period.End).To(Equal(time.Date(2017, time.April, 1, 0, 0, 0, 0, time.UTC).Add(-time.Nanosecond)))
+		Expect(period.Label()).To(Equal("2017-01-01 - 2017-03-31"))
+	})
+
+	It("parses a range of timestamps and an open end", func() {
+		period, err := deployments.ParsePeriod("2017-01-10T08:00:00Z..", time.UTC, now)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(period.Start).To(Equal(time.Date(2017, time.January, 10, 8, 0, 0, 0, time.UTC)))
+		Expect(period.End).To(Equal(now))
+	})
+
+	It("returns validation errors instead of panicking", func() {
+		for _, spec := range []string{"", "2017", "2017/13", "last 0d", "2017-02-01..2017-01-01", "yesterday..", "..2017-01-01", "2017/01..2017/13"} {
+			_, err := deployments.ParsePeriod(spec, time.UTC, now)
+			Expect(err).To(HaveOccurred(), spec)
+		}
+	})
+})
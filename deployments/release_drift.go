@@ -0,0 +1,90 @@
+package deployments
+
+import (
+	"errors"
+	"sort"
+
+	boshdir "github.com/cloudfoundry/bosh-cli/director"
+	boshlog "github.com/cloudfoundry/bosh-utils/logger"
+)
+
+type ReleaseDrift struct {
+	Release        string `json:"release"`
+	Deployment     string `json:"deployment"`
+	Version        string `json:"version"`
+	NewestVersion  string `json:"newest_version"`
+	VersionsBehind int    `json:"versions_behind"`
+}
+
+func (d *DeployCounter) ReleaseDrift(release string) ([]ReleaseDrift, error) {
+	logger := boshlog.NewLogger(boshlog.LevelError)
+
+	if d.EventsFile != "" {
+		return nil, errors.New("the release drift report lists the director's current deployments and cannot be computed from an events file")
+	}
+
+	directorClient, err := createDirectorClient(d, logger)
+	if err != nil {
+		return nil, err
+	}
+
+	directorDeployments, err := directorClient.Deployments()
+	if err != nil {
+		return nil, err
+	}
+
+	return computeReleaseDrift(directorDeployments, release)
+}
+
+func computeReleaseDrift(directorDeployments []boshdir.Deployment, release string) ([]ReleaseDrift, error) {
+	drifts := []ReleaseDrift{}
+	deployedVersions := make(map[string][]string)
+
+	for _, deployment := range directorDeployments {
+		releases, err := deployment.Releases()
+		if err != nil {
+			return nil, err
+		}
+
+		for _, deployedRelease := range releases {
+			if release != "" && deployedRelease.Name() != release {
+				continue
+			}
+
+			version := deployedRelease.Version().String()
+			drifts = append(drifts, ReleaseDrift{
+				Release:    deployedRelease.Name(),
+				Deployment: deployment.Name(),
+				Version:    version,
+			})
+
+			if !containsVersion(deployedVersions[deployedRelease.Name()], version) {
+				deployedVersions[deployedRelease.Name()] = append(deployedVersions[deployedRelease.Name()], version)
+			}
+		}
+	}
+
+	for i, drift := range drifts {
+		versions := deployedVersions[drift.Release]
+		drifts[i].NewestVersion = highestVersion(versions)
+		for _, version := range versions {
+			if isOlderVersion(drift.Version, version) {
+				drifts[i].VersionsBehind += 1
+			}
+		}
+	}
+
+	sort.Sort(byReleaseAndDeployment(drifts))
+	return drifts, nil
+}
+
+type byReleaseAndDeployment []ReleaseDrift
+
+func (r byReleaseAndDeployment) Len() int { return len(r) }
+func (r byReleaseAndDeployment) Less(i, j int) bool {
+	if r[i].Release != r[j].Release {
+		return r[i].Release < r[j].Release
+	}
+	return r[i].Deployment < r[j].Deployment
+}
+func (r byReleaseAndDeployment) Swap(i, j int) { r[i], r[j] = r[j], r[i] }
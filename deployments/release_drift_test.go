@@ -0,0 +1,63 @@
+package deployments_test
+
+import (
+	"net/http"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/onsi/gomega/ghttp"
+	"github.com/pivotal-cloudops/bosh-stats/deployments"
+)
+
+var _ = Describe("Release drift", func() {
+	deploymentsResponse := `
+	[
+		{
+			"name": "cf-staging",
+			"releases": [{"name": "cf", "version": "251"}, {"name": "routing", "version": "0.150.0"}],
+			"stemcells": [{"name": "ubuntu-trusty", "version": "3421.11"}]
+		},
+		{
+			"name": "cf",
+			"releases": [{"name": "cf", "version": "250"}, {"name": "routing", "version": "0.150.0"}],
+			"stemcells": [{"name": "ubuntu-trusty", "version": "3421.11"}]
+		},
+		{
+			"name": "cf-canary",
+			"releases": [{"name": "cf", "version": "252"}],
+			"stemcells": [{"name": "ubuntu-trusty", "version": "3421.11"}]
+		}
+	]`
+
+	fake := serveDirector(
+		ghttp.CombineHandlers(
+			ghttp.VerifyRequest("GET", "/deployments"),
+			ghttp.RespondWith(http.StatusOK, deploymentsResponse),
+		),
+	)
+
+	It("shows how many deployed versions each deployment is behind the newest one", func() {
+		drifts, err := fake.deployCounter.ReleaseDrift("")
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(drifts).To(Equal([]deployments.ReleaseDrift{
+			{Release: "cf", Deployment: "cf", Version: "250", NewestVersion: "252", VersionsBehind: 2},
+			{Release: "cf", Deployment: "cf-canary", Version: "252", NewestVersion: "252", VersionsBehind: 0},
+			{Release: "cf", Deployment: "cf-staging", Version: "251", NewestVersion: "252", VersionsBehind: 1},
+			{Release: "routing", Deployment: "cf", Version: "0.150.0", NewestVersion: "0.150.0", VersionsBehind: 0},
+			{Release: "routing", Deployment: "cf-staging", Version: "0.150.0", NewestVersion: "0.150.0", VersionsBehind: 0},
+		}))
+	})
+
+	It("only lists the given release", func() {
+		drifts, err := fake.deployCounter.ReleaseDrift("routing")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(drifts).To(HaveLen(2))
+	})
+
+	It("needs a director rather than an events file", func() {
+		fake.deployCounter.EventsFile = "events.jsonl"
+		_, err := fake.deployCounter.ReleaseDrift("")
+		Expect(err).To(HaveOccurred())
+	})
+})
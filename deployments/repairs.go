@@ -0,0 +1,195 @@
+package deployments
+
+import (
+	"encoding/json"
+	"sort"
+	"strconv"
+	"time"
+
+	boshdir "github.com/cloudfoundry/bosh-cli/director"
+	boshlog "github.com/cloudfoundry/bosh-utils/logger"
+)
+
+const cloudCheckTaskDescription = "apply resolutions"
+
+// cloudCheckActions are the instance and VM actions that cloud check
+// resolutions perform; tasks with only other actions are not looked up.
+var cloudCheckActions = map[string]bool{
+	"create":   true,
+	"delete":   true,
+	"recreate": true,
+	"reboot":   true,
+}
+
+type RepairStats struct {
+	Resurrections                int
+	CloudCheckRepairs            int
+	MeanTimeBetweenResurrections time.Duration
+}
+
+type repairAccumulator struct {
+	resurrections     map[string]time.Time
+	cloudCheckRepairs map[string]bool
+}
+
+// Repairs counts the VMs resurrected by the health monitor and repaired by
+// cloud check per deployment and instance group. Repairs are told apart by
+// task: every instance a health monitor or cloud check task touched counts
+// once. Cloud check tasks are recognised by their description, which needs
+// the director, so they are not counted when reading events from a file.
+func (d *DeployCounter) Repairs(period string, itemsPerPage int, healthMonitorUser string, deployment string) (map[string]map[string]RepairStats, error) {
+	healthMonitor, err := ParseUserMatcher(healthMonitorUser)
+	if err != nil {
+		return nil, err
+	}
+
+	logger := boshlog.NewLogger(boshlog.LevelError)
+
+	reportingPeriod, err := d.reportingPeriod(period)
+	if err != nil {
+		return nil, err
+	}
+	opts := createCalendarOpts(reportingPeriod, deployment)
+
+	eventSource, directorClient, err := createEventSourceAndDirector(d, logger, itemsPerPage)
+	if err != nil {
+		return nil, err
+	}
+
+	accumulators := make(map[string]map[string]*repairAccumulator)
+	taskDescriptions := make(map[string]string)
+	deployTasks := make(map[string]bool)
+
+	var taskErr error
+	err = reduceDeploymentsToCount(eventSource, []boshdir.Event{}, opts, itemsPerPage, func(events []boshdir.Event) {
+		if taskErr == nil {
+			taskErr = repairEventCount(events, accumulators, healthMonitor, directorClient, taskDescriptions, deployTasks)
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+	if taskErr != nil {
+		return nil, taskErr
+	}
+
+	stats := make(map[string]map[string]RepairStats)
+	for deploymentName, byInstanceGroup := range accumulators {
+		stats[deploymentName] = make(map[string]RepairStats)
+		for instanceGroup, accumulator := range byInstanceGroup {
+			stats[deploymentName][instanceGroup] = accumulator.stats()
+		}
+	}
+	return stats, nil
+}
+
+func repairEventCount(events []boshdir.Event, accumulators map[string]map[string]*repairAccumulator, healthMonitor UserMatcher, directorClient boshdir.Director, taskDescriptions map[string]string, deployTasks map[string]bool) error {
+	for _, event := range events {
+		// A deploy ends with a deployment event newer than the instance
+		// events of its task, so they are known not to be cloud check.
+		if event.ObjectType() == "deployment" {
+			deployTasks[event.TaskID()] = true
+			continue
+		}
+		if event.ObjectType() != "instance" && event.ObjectType() != "vm" {
+			continue
+		}
+		if event.Instance() == "" || event.Error() != "" {
+			continue
+		}
+
+		repair := event.TaskID() + " " + event.Instance()
+
+		if healthMonitor.Matches(event.User()) {
+			accumulator := repairAccumulatorFor(accumulators, event)
+			// Events are newest first, so the earliest event of a task wins.
+			accumulator.resurrections[repair] = event.Timestamp()
+			continue
+		}
+
+		if directorClient == nil || deployTasks[event.TaskID()] || !cloudCheckActions[event.Action()] {
+			continue
+		}
+
+		description, err := cachedTaskDescription(directorClient, event.TaskID(), taskDescriptions)
+		if err != nil {
+			return err
+		}
+		if description == cloudCheckTaskDescription {
+			repairAccumulatorFor(accumulators, event).cloudCheckRepairs[repair] = true
+		}
+	}
+	return nil
+}
+
+func cachedTaskDescription(directorClient boshdir.Director, taskID string, taskDescriptions map[string]string) (string, error) {
+	if description, ok := taskDescriptions[taskID]; ok {
+		return description, nil
+	}
+
+	id, err := strconv.Atoi(taskID)
+	if err != nil {
+		taskDescriptions[taskID] = ""
+		return "", nil
+	}
+
+	task, err := directorClient.FindTask(id)
+	if err != nil {
+		return "", err
+	}
+
+	taskDescriptions[taskID] = task.Description()
+	return task.Description(), nil
+}
+
+func repairAccumulatorFor(accumulators map[string]map[string]*repairAccumulator, event boshdir.Event) *repairAccumulator {
+	deploymentName := event.DeploymentName()
+	if accumulators[deploymentName] == nil {
+		accumulators[deploymentName] = make(map[string]*repairAccumulator)
+	}
+
+	instanceGroup := InstanceGroup(event.Instance())
+	accumulator, ok := accumulators[deploymentName][instanceGroup]
+	if !ok {
+		accumulator = &repairAccumulator{
+			resurrections:     make(map[string]time.Time),
+			cloudCheckRepairs: make(map[string]bool),
+		}
+		accumulators[deploymentName][instanceGroup] = accumulator
+	}
+	return accumulator
+}
+
+func (a *repairAccumulator) stats() RepairStats {
+	resurrectionTimes := []time.Time{}
+	for _, timestamp := range a.resurrections {
+		resurrectionTimes = append(resurrectionTimes, timestamp)
+	}
+	sort.Sort(byTime(resurrectionTimes))
+
+	var meanTimeBetween time.Duration
+	if len(resurrectionTimes) > 1 {
+		span := resurrectionTimes[len(resurrectionTimes)-1].Sub(resurrectionTimes[0])
+		meanTimeBetween = span / time.Duration(len(resurrectionTimes)-1)
+	}
+
+	return RepairStats{
+		Resurrections:                len(a.resurrections),
+		CloudCheckRepairs:            len(a.cloudCheckRepairs),
+		MeanTimeBetweenResurrections: meanTimeBetween,
+	}
+}
+
+func (s RepairStats) MarshalJSON() ([]byte, error) {
+	return json.Marshal(map[string]interface{}{
+		"resurrections":                           s.Resurrections,
+		"cloud_check_repairs":                     s.CloudCheckRepairs,
+		"mean_time_between_resurrections_seconds": s.MeanTimeBetweenResurrections.Seconds(),
+	})
+}
+
+type byTime []time.Time
+
+func (t byTime) Len() int           { return len(t) }
+func (t byTime) Less(i, j int) bool { return t[i].Before(t[j]) }
+func (t byTime) Swap(i, j int)      { t[i], t[j] = t[j], t[i] }
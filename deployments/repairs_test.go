@@ -0,0 +1,62 @@
+package deployments_test
+
+import (
+	"net/http"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/onsi/gomega/ghttp"
+)
+
+var _ = Describe("Repairs", func() {
+	events := `
+	[
+		{"id": "12", "timestamp": 1447009000, "user": "admin", "action": "update", "object_type": "deployment", "task": "40", "deployment": "cf", "context": {"before": {}, "after": {}}},
+		{"id": "11", "timestamp": 1447008900, "user": "admin", "action": "recreate", "object_type": "instance", "task": "40", "deployment": "cf", "instance": "router/2"},
+		{"id": "10", "timestamp": 1447008000, "user": "admin", "action": "stop", "object_type": "instance", "task": "50", "deployment": "cf", "instance": "router/3"},
+		{"id": "9", "timestamp": 1447007200, "user": "admin", "action": "recreate", "object_type": "instance", "task": "30", "deployment": "cf", "instance": "router/1"},
+		{"id": "8", "timestamp": 1447007100, "user": "admin", "action": "recreate", "object_type": "instance", "task": "20", "deployment": "cf", "instance": "router/0"},
+		{"id": "7", "timestamp": 1447007000, "user": "admin", "action": "create", "object_type": "vm", "task": "20", "deployment": "cf", "instance": "router/0"},
+		{"id": "6", "timestamp": 1447003600, "user": "hm", "action": "create", "object_type": "vm", "task": "12", "deployment": "cf", "instance": "diego_cell/2"},
+		{"id": "5", "timestamp": 1447003500, "user": "hm", "action": "delete", "object_type": "vm", "task": "12", "deployment": "cf", "instance": "diego_cell/2"},
+		{"id": "4", "timestamp": 1447001800, "user": "hm", "action": "create", "object_type": "vm", "task": "11", "deployment": "cf", "instance": "diego_cell/1", "error": "quota exceeded"},
+		{"id": "3", "timestamp": 1447000000, "user": "hm", "action": "create", "object_type": "vm", "task": "10", "deployment": "cf", "instance": "diego_cell/0"},
+		{"id": "2", "timestamp": 1446999000, "user": "hm", "action": "create", "object_type": "vm", "task": "10", "deployment": "cf", "instance": "diego_cell/1"},
+		{"id": "1", "timestamp": 1446998000, "user": "hm", "action": "update", "object_type": "deployment", "task": "10", "deployment": "cf", "context": {"before": {}, "after": {}}}
+	]`
+
+	fake := serveDirector(
+		serveEvents(events, "before_time=1448927999&after_time=1446336000"),
+		ghttp.CombineHandlers(
+			ghttp.VerifyRequest("GET", "/tasks/30"),
+			ghttp.RespondWith(http.StatusOK, `{"id": 30, "state": "done", "description": "create deployment"}`),
+		),
+		ghttp.CombineHandlers(
+			ghttp.VerifyRequest("GET", "/tasks/20"),
+			ghttp.RespondWith(http.StatusOK, `{"id": 20, "state": "done", "description": "apply resolutions"}`),
+		),
+	)
+
+	It("counts resurrected and cloud check repaired instances per deployment and instance group", func() {
+		stats, err := fake.deployCounter.Repairs("2015/11", 999, "hm", "")
+		Expect(err).NotTo(HaveOccurred())
+
+		// Deploy tasks and actions cloud check never performs are not looked up.
+		Expect(fake.director.ReceivedRequests()).To(HaveLen(3))
+
+		Expect(stats["cf"]["diego_cell"].Resurrections).To(Equal(3))
+		Expect(stats["cf"]["diego_cell"].CloudCheckRepairs).To(Equal(0))
+		Expect(stats["cf"]["router"].Resurrections).To(Equal(0))
+		Expect(stats["cf"]["router"].CloudCheckRepairs).To(Equal(1))
+	})
+
+	It("computes the mean time between resurrections", func() {
+		stats, err := fake.deployCounter.Repairs("2015/11", 999, "hm", "")
+		Expect(err).NotTo(HaveOccurred())
+
+		// Resurrections at 1446999000, 1447000000 and 1447003500.
+		Expect(stats["cf"]["diego_cell"].MeanTimeBetweenResurrections).To(Equal(2250 * time.Second))
+		Expect(stats["cf"]["router"].MeanTimeBetweenResurrections).To(Equal(time.Duration(0)))
+	})
+})
@@ -0,0 +1,154 @@
+package deployments
+
+import (
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/blang/semver"
+	boshdir "github.com/cloudfoundry/bosh-cli/director"
+	boshlog "github.com/cloudfoundry/bosh-utils/logger"
+)
+
+type Rollout struct {
+	Deployment      string    `json:"deployment"`
+	Timestamp       time.Time `json:"rolled_out_at"`
+	User            string    `json:"user"`
+	PreviousVersion string    `json:"previous_version"`
+}
+
+type DeploymentVersion struct {
+	Deployment string `json:"deployment"`
+	Version    string `json:"version"`
+}
+
+type RolloutReport struct {
+	Release  string              `json:"release,omitempty"`
+	Stemcell string              `json:"stemcell,omitempty"`
+	Version  string              `json:"version"`
+	Rollouts []Rollout           `json:"rollouts"`
+	Behind   []DeploymentVersion `json:"behind"`
+}
+
+func (d *DeployCounter) ReleaseRollout(release string, version string, itemsPerPage int) (RolloutReport, error) {
+	report, err := d.rollout("releases", release, version, itemsPerPage)
+	report.Release = release
+	return report, err
+}
+
+func (d *DeployCounter) StemcellRollout(stemcell string, version string, itemsPerPage int) (RolloutReport, error) {
+	report, err := d.rollout("stemcells", stemcell, version, itemsPerPage)
+	report.Stemcell = stemcell
+	return report, err
+}
+
+func (d *DeployCounter) rollout(key string, name string, version string, itemsPerPage int) (RolloutReport, error) {
+	logger := boshlog.NewLogger(boshlog.LevelError)
+
+	eventSource, err := createEventSource(d, logger, itemsPerPage)
+	if err != nil {
+		return RolloutReport{}, err
+	}
+
+	events := []boshdir.Event{}
+	err = reduceDeploymentsToCount(eventSource, []boshdir.Event{}, boshdir.EventsFilter{}, itemsPerPage, func(newEvents []boshdir.Event) {
+		events = append(events, newEvents...)
+	})
+	if err != nil {
+		return RolloutReport{}, err
+	}
+
+	return computeRollout(events, key, name, version), nil
+}
+
+// computeRollout expects events newest first, as returned by the director.
+func computeRollout(events []boshdir.Event, key string, name string, version string) RolloutReport {
+	report := RolloutReport{Version: version, Rollouts: []Rollout{}, Behind: []DeploymentVersion{}}
+
+	rollouts := make(map[string]Rollout)
+	currentVersions := make(map[string]string)
+	seen := make(map[string]bool)
+
+	for _, event := range events {
+		if event.ObjectType() != "deployment" {
+			continue
+		}
+		deploymentName := event.DeploymentName()
+
+		// The newest event of a deployment tells which version it runs now,
+		// unless the deployment has since been deleted.
+		if !seen[deploymentName] && (event.Action() == "delete" || isDeployment(event)) {
+			seen[deploymentName] = true
+			versions, _ := contextVersions(event, "after", key, name)
+			if isDeployment(event) && len(versions) > 0 {
+				currentVersions[deploymentName] = highestVersion(versions)
+			}
+		}
+
+		if !isDeployment(event) {
+			continue
+		}
+
+		after, _ := contextVersions(event, "after", key, name)
+		before, _ := contextVersions(event, "before", key, name)
+		if containsVersion(after, version) && !containsVersion(before, version) {
+			// Older events come later, so the first rollout wins.
+			rollouts[deploymentName] = Rollout{
+				Deployment:      deploymentName,
+				Timestamp:       event.Timestamp(),
+				User:            event.User(),
+				PreviousVersion: strings.Join(before, ", "),
+			}
+		}
+	}
+
+	for _, rollout := range rollouts {
+		report.Rollouts = append(report.Rollouts, rollout)
+	}
+	sort.Sort(byRolloutTime(report.Rollouts))
+
+	for deploymentName, currentVersion := range currentVersions {
+		if isOlderVersion(currentVersion, version) {
+			report.Behind = append(report.Behind, DeploymentVersion{Deployment: deploymentName, Version: currentVersion})
+		}
+	}
+	sort.Sort(byDeploymentName(report.Behind))
+
+	return report
+}
+
+func highestVersion(versions []string) string {
+	highest := ""
+	for _, version := range versions {
+		if highest == "" || isOlderVersion(highest, version) {
+			highest = version
+		}
+	}
+	return highest
+}
+
+func isOlderVersion(version string, otherVersion string) bool {
+	semverVersion, err := semver.ParseTolerant(version)
+	if err != nil {
+		return false
+	}
+
+	otherSemverVersion, err := semver.ParseTolerant(otherVersion)
+	if err != nil {
+		return false
+	}
+
+	return semverVersion.LT(otherSemverVersion)
+}
+
+type byRolloutTime []Rollout
+
+func (r byRolloutTime) Len() int           { return len(r) }
+func (r byRolloutTime) Less(i, j int) bool { return r[i].Timestamp.Before(r[j].Timestamp) }
+func (r byRolloutTime) Swap(i, j int)      { r[i], r[j] = r[j], r[i] }
+
+type byDeploymentName []DeploymentVersion
+
+func (d byDeploymentName) Len() int           { return len(d) }
+func (d byDeploymentName) Less(i, j int) bool { return d[i].Deployment < d[j].Deployment }
+func (d byDeploymentName) Swap(i, j int)      { d[i], d[j] = d[j], d[i] }
@@ -0,0 +1,108 @@
+package deployments_test
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/pivotal-cloudops/bosh-stats/deployments"
+)
+
+var _ = Describe("Rollout", func() {
+	events := `
+	[
+		{
+			"id": "7",
+			"action": "update",
+			"timestamp": 1447030000,
+			"user": "admin",
+			"object_type": "deployment",
+			"deployment": "cf-staging",
+			"context": {"before": {"releases": ["cf/123"]}, "after": {"releases": ["cf/123"]}}
+		},
+		{
+			"id": "6",
+			"action": "delete",
+			"timestamp": 1447025000,
+			"user": "admin",
+			"object_type": "deployment",
+			"deployment": "cf-old"
+		},
+		{
+			"id": "5",
+			"action": "update",
+			"timestamp": 1447020000,
+			"user": "pipeline",
+			"object_type": "deployment",
+			"deployment": "cf",
+			"context": {"before": {"releases": ["cf/122"], "stemcells": ["ubuntu-trusty/3421.9"]}, "after": {"releases": ["cf/123"], "stemcells": ["ubuntu-trusty/3421.11"]}}
+		},
+		{
+			"id": "4",
+			"action": "update",
+			"timestamp": 1447010000,
+			"user": "admin",
+			"error": "failed",
+			"object_type": "deployment",
+			"deployment": "cf",
+			"context": {"before": {"releases": ["cf/122"]}, "after": {"releases": ["cf/123"]}}
+		},
+		{
+			"id": "3",
+			"action": "update",
+			"timestamp": 1447005000,
+			"user": "admin",
+			"object_type": "deployment",
+			"deployment": "cf-staging",
+			"context": {"before": {"releases": ["cf/121"]}, "after": {"releases": ["cf/123"]}}
+		},
+		{
+			"id": "2",
+			"action": "update",
+			"timestamp": 1447003000,
+			"user": "admin",
+			"object_type": "deployment",
+			"deployment": "cf-canary",
+			"context": {"before": {"releases": ["cf/121"]}, "after": {"releases": ["cf/122", "routing/0.150.0"]}}
+		},
+		{
+			"id": "1",
+			"action": "update",
+			"timestamp": 1447000000,
+			"user": "admin",
+			"object_type": "deployment",
+			"deployment": "cf-old",
+			"context": {"before": {"releases": ["cf/120"]}, "after": {"releases": ["cf/121"]}}
+		}
+	]`
+
+	fake := serveDirector(serveEvents(events))
+
+	It("lists when each deployment first got the version, oldest first", func() {
+		report, err := fake.deployCounter.ReleaseRollout("cf", "123", 999)
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(report.Rollouts).To(Equal([]deployments.Rollout{
+			{Deployment: "cf-staging", Timestamp: time.Unix(1447005000, 0).UTC(), User: "admin", PreviousVersion: "121"},
+			{Deployment: "cf", Timestamp: time.Unix(1447020000, 0).UTC(), User: "pipeline", PreviousVersion: "122"},
+		}))
+	})
+
+	It("lists deployments still running an older version, ignoring deleted ones", func() {
+		report, err := fake.deployCounter.ReleaseRollout("cf", "123", 999)
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(report.Behind).To(Equal([]deployments.DeploymentVersion{
+			{Deployment: "cf-canary", Version: "122"},
+		}))
+	})
+	It("follows a stemcell version the same way", func() {
+		report, err := fake.deployCounter.StemcellRollout("ubuntu-trusty", "3421.11", 999)
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(report.Stemcell).To(Equal("ubuntu-trusty"))
+		Expect(report.Rollouts).To(Equal([]deployments.Rollout{
+			{Deployment: "cf", Timestamp: time.Unix(1447020000, 0).UTC(), User: "pipeline", PreviousVersion: "3421.9"},
+		}))
+	})
+})
@@ -0,0 +1,136 @@
+package deployments
+
+import (
+	"sort"
+	"strings"
+	"time"
+
+	boshdir "github.com/cloudfoundry/bosh-cli/director"
+	boshlog "github.com/cloudfoundry/bosh-utils/logger"
+)
+
+type StemcellBump struct {
+	Timestamp time.Time `json:"timestamp"`
+	User      string    `json:"user"`
+	Stemcell  string    `json:"stemcell"`
+	From      string    `json:"from"`
+	To        string    `json:"to"`
+}
+
+func (d *DeployCounter) StemcellBumps(period string, itemsPerPage int, repaveUser string, runningBumps *map[string][]StemcellBump, deployment string) error {
+	if _, err := cachedUserMatcher(repaveUser); err != nil {
+		return err
+	}
+
+	logger := boshlog.NewLogger(boshlog.LevelError)
+
+	reportingPeriod, err := d.reportingPeriod(period)
+	if err != nil {
+		return err
+	}
+	opts := createCalendarOpts(reportingPeriod, deployment)
+
+	eventSource, err := createEventSource(d, logger, itemsPerPage)
+	if err != nil {
+		return err
+	}
+
+	err = reduceDeploymentsToCount(eventSource, []boshdir.Event{}, opts, itemsPerPage, func(events []boshdir.Event) {
+		stemcellBumpCount(events, runningBumps, repaveUser)
+	})
+	if err != nil {
+		return err
+	}
+
+	for deploymentName := range *runningBumps {
+		sort.Sort(byBumpTime((*runningBumps)[deploymentName]))
+	}
+	return nil
+}
+
+func stemcellBumpCount(events []boshdir.Event, runningBumps *map[string][]StemcellBump, repaveUser string) {
+	for _, event := range events {
+		if isDeployment(event) && IsNotRepaveUser(event, repaveUser) {
+			bumps := stemcellBumpsFromEvent(event)
+			if len(bumps) > 0 {
+				deploymentName := event.DeploymentName()
+				(*runningBumps)[deploymentName] = append((*runningBumps)[deploymentName], bumps...)
+			}
+		}
+	}
+}
+
+func stemcellBumpsFromEvent(event boshdir.Event) []StemcellBump {
+	before, ok := contextEntries(event, "before", "stemcells")
+	if !ok || len(before) == 0 {
+		return nil
+	}
+
+	after, ok := contextEntries(event, "after", "stemcells")
+	if !ok {
+		return nil
+	}
+
+	stemcellNames := []string{}
+	for stemcellName := range after {
+		stemcellNames = append(stemcellNames, stemcellName)
+	}
+	sort.Strings(stemcellNames)
+
+	bumps := []StemcellBump{}
+	for _, stemcellName := range stemcellNames {
+		versionsBefore := before[stemcellName]
+		versionsAfter := after[stemcellName]
+		if sameVersions(versionsBefore, versionsAfter) {
+			continue
+		}
+
+		from := strings.Join(versionsBefore, ", ")
+		if len(versionsBefore) == 0 {
+			// A stemcell line switch, e.g. from trusty to xenial, replaces
+			// whatever stemcells the deployment no longer uses.
+			from = replacedStemcells(before, after)
+		}
+
+		bumps = append(bumps, StemcellBump{
+			Timestamp: event.Timestamp(),
+			User:      event.User(),
+			Stemcell:  stemcellName,
+			From:      from,
+			To:        strings.Join(versionsAfter, ", "),
+		})
+	}
+	return bumps
+}
+
+func replacedStemcells(before map[string][]string, after map[string][]string) string {
+	replaced := []string{}
+	for stemcellName, versions := range before {
+		if _, ok := after[stemcellName]; ok {
+			continue
+		}
+		for _, version := range versions {
+			replaced = append(replaced, stemcellName+"/"+version)
+		}
+	}
+	sort.Strings(replaced)
+	return strings.Join(replaced, ", ")
+}
+
+func sameVersions(versions []string, otherVersions []string) bool {
+	if len(versions) != len(otherVersions) {
+		return false
+	}
+	for _, version := range versions {
+		if !containsVersion(otherVersions, version) {
+			return false
+		}
+	}
+	return true
+}
+
+type byBumpTime []StemcellBump
+
+func (b byBumpTime) Len() int           { return len(b) }
+func (b byBumpTime) Less(i, j int) bool { return b[i].Timestamp.Before(b[j].Timestamp) }
+func (b byBumpTime) Swap(i, j int)      { b[i], b[j] = b[j], b[i] }
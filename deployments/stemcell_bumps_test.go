@@ -0,0 +1,90 @@
+package deployments_test
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/pivotal-cloudops/bosh-stats/deployments"
+)
+
+var _ = Describe("Stemcell bumps", func() {
+	events := `
+	[
+		{
+			"id": "5",
+			"action": "update",
+			"timestamp": 1447030000,
+			"user": "admin",
+			"object_type": "deployment",
+			"deployment": "cf",
+			"context": {
+				"before": {"releases": ["cf/123"], "stemcells": ["ubuntu-trusty/3421.11"]},
+				"after": {"releases": ["cf/123"], "stemcells": ["ubuntu-xenial/97.3"]}
+			}
+		},
+		{
+			"id": "4",
+			"action": "update",
+			"timestamp": 1447020000,
+			"user": "repave",
+			"object_type": "deployment",
+			"deployment": "diego",
+			"context": {
+				"before": {"stemcells": ["ubuntu-trusty/3421.9"]},
+				"after": {"stemcells": ["ubuntu-trusty/3421.11"]}
+			}
+		},
+		{
+			"id": "3",
+			"action": "update",
+			"timestamp": 1447010000,
+			"user": "admin",
+			"object_type": "deployment",
+			"deployment": "cf",
+			"context": {
+				"before": {"releases": ["cf/122"], "stemcells": ["ubuntu-trusty/3421.11"]},
+				"after": {"releases": ["cf/123"], "stemcells": ["ubuntu-trusty/3421.11"]}
+			}
+		},
+		{
+			"id": "2",
+			"action": "update",
+			"timestamp": 1447005000,
+			"user": "pipeline",
+			"object_type": "deployment",
+			"deployment": "cf",
+			"context": {
+				"before": {"stemcells": ["ubuntu-trusty/3421.9"]},
+				"after": {"stemcells": ["ubuntu-trusty/3421.11"]}
+			}
+		},
+		{
+			"id": "1",
+			"action": "create",
+			"timestamp": 1447000000,
+			"user": "admin",
+			"object_type": "deployment",
+			"deployment": "mysql",
+			"context": {
+				"before": {},
+				"after": {"stemcells": ["ubuntu-trusty/3421.11"]}
+			}
+		}
+	]`
+
+	fake := serveDirector(serveEvents(events, "before_time=1448927999&after_time=1446336000"))
+
+	It("lists the stemcell bumps of each deployment oldest first, skipping the repave user", func() {
+		bumps := make(map[string][]deployments.StemcellBump)
+		err := fake.deployCounter.StemcellBumps("2015/11", 999, "repave", &bumps, "")
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(bumps).To(Equal(map[string][]deployments.StemcellBump{
+			"cf": {
+				{Timestamp: time.Unix(1447005000, 0).UTC(), User: "pipeline", Stemcell: "ubuntu-trusty", From: "3421.9", To: "3421.11"},
+				{Timestamp: time.Unix(1447030000, 0).UTC(), User: "admin", Stemcell: "ubuntu-xenial", From: "ubuntu-trusty/3421.11", To: "97.3"},
+			},
+		}))
+	})
+})
@@ -0,0 +1,54 @@
+package deployments
+
+import (
+	"time"
+
+	boshdir "github.com/cloudfoundry/bosh-cli/director"
+)
+
+// StoredEvent is a director event in the same JSON shape as the director's
+// /events API, so it can be written to and read back from local files.
+type StoredEvent struct {
+	EventID         string                 `json:"id"`
+	EventParentID   string                 `json:"parent_id,omitempty"`
+	EventTimestamp  int64                  `json:"timestamp"`
+	EventUser       string                 `json:"user"`
+	EventAction     string                 `json:"action"`
+	EventObjectType string                 `json:"object_type"`
+	EventObjectName string                 `json:"object_name"`
+	EventTaskID     string                 `json:"task"`
+	EventDeployment string                 `json:"deployment"`
+	EventInstance   string                 `json:"instance"`
+	EventContext    map[string]interface{} `json:"context"`
+	EventError      string                 `json:"error"`
+}
+
+func NewStoredEvent(event boshdir.Event) StoredEvent {
+	return StoredEvent{
+		EventID:         event.ID(),
+		EventParentID:   event.ParentID(),
+		EventTimestamp:  event.Timestamp().Unix(),
+		EventUser:       event.User(),
+		EventAction:     event.Action(),
+		EventObjectType: event.ObjectType(),
+		EventObjectName: event.ObjectName(),
+		EventTaskID:     event.TaskID(),
+		EventDeployment: event.DeploymentName(),
+		EventInstance:   event.Instance(),
+		EventContext:    event.Context(),
+		EventError:      event.Error(),
+	}
+}
+
+func (e StoredEvent) ID() string                      { return e.EventID }
+func (e StoredEvent) ParentID() string                { return e.EventParentID }
+func (e StoredEvent) Timestamp() time.Time            { return time.Unix(e.EventTimestamp, 0).UTC() }
+func (e StoredEvent) User() string                    { return e.EventUser }
+func (e StoredEvent) Action() string                  { return e.EventAction }
+func (e StoredEvent) ObjectType() string              { return e.EventObjectType }
+func (e StoredEvent) ObjectName() string              { return e.EventObjectName }
+func (e StoredEvent) TaskID() string                  { return e.EventTaskID }
+func (e StoredEvent) DeploymentName() string          { return e.EventDeployment }
+func (e StoredEvent) Instance() string                { return e.EventInstance }
+func (e StoredEvent) Context() map[string]interface{} { return e.EventContext }
+func (e StoredEvent) Error() string                   { return e.EventError }
@@ -0,0 +1,136 @@
+package deployments
+
+import (
+	"encoding/json"
+	"sort"
+	"time"
+
+	boshdir "github.com/cloudfoundry/bosh-cli/director"
+	boshlog "github.com/cloudfoundry/bosh-utils/logger"
+)
+
+// Trend holds successful deploys per deployment for each calendar month of
+// a reporting period, in the order of Months.
+type Trend struct {
+	Months []Period
+	Counts map[string][]int
+}
+
+func (d *DeployCounter) DeployTrend(period string, itemsPerPage int, repaveUser string, deployment string) (Trend, error) {
+	if _, err := cachedUserMatcher(repaveUser); err != nil {
+		return Trend{}, err
+	}
+
+	logger := boshlog.NewLogger(boshlog.LevelError)
+
+	reportingPeriod, err := d.reportingPeriod(period)
+	if err != nil {
+		return Trend{}, err
+	}
+	trend := NewTrend(reportingPeriod)
+	opts := createCalendarOpts(reportingPeriod, deployment)
+
+	eventSource, err := createEventSource(d, logger, itemsPerPage)
+	if err != nil {
+		return trend, err
+	}
+
+	err = reduceDeploymentsToCount(eventSource, []boshdir.Event{}, opts, itemsPerPage, func(events []boshdir.Event) {
+		for _, event := range events {
+			if isDeployment(event) && IsNotRepaveUser(event, repaveUser) {
+				trend.Add(event.DeploymentName(), event.Timestamp())
+			}
+		}
+	})
+	if err != nil {
+		return trend, err
+	}
+
+	return trend, nil
+}
+
+// NewTrend splits period into calendar months, clipping the first and last
+// month to the period.
+func NewTrend(period Period) Trend {
+	trend := Trend{Counts: make(map[string][]int)}
+
+	start := period.Start
+	for !start.After(period.End) {
+		monthStart := time.Date(start.Year(), start.Month(), 1, 0, 0, 0, 0, start.Location())
+		month := newPeriod(start, monthStart.AddDate(0, 1, 0))
+		if month.End.After(period.End) {
+			month.End = period.End
+		}
+
+		trend.Months = append(trend.Months, month)
+		start = monthStart.AddDate(0, 1, 0)
+	}
+	return trend
+}
+
+func (t *Trend) Add(deployment string, timestamp time.Time) {
+	for i, month := range t.Months {
+		if !timestamp.Before(month.Start) && !timestamp.After(month.End) {
+			if _, ok := t.Counts[deployment]; !ok {
+				t.Counts[deployment] = make([]int, len(t.Months))
+			}
+			t.Counts[deployment][i] += 1
+			return
+		}
+	}
+}
+
+func (t Trend) Deployments() []string {
+	names := []string{}
+	for name := range t.Counts {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func (t Trend) Totals() []int {
+	totals := make([]int, len(t.Months))
+	for _, counts := range t.Counts {
+		for i, count := range counts {
+			totals[i] += count
+		}
+	}
+	return totals
+}
+
+// Deltas returns the change of each month against the month before, so the
+// first month has none and is left out.
+func Deltas(counts []int) []int {
+	deltas := []int{}
+	for i := 1; i < len(counts); i++ {
+		deltas = append(deltas, counts[i]-counts[i-1])
+	}
+	return deltas
+}
+
+func (t Trend) MarshalJSON() ([]byte, error) {
+	type trendRow struct {
+		Deployment string `json:"deployment"`
+		Counts     []int  `json:"counts"`
+		Deltas     []int  `json:"deltas"`
+	}
+
+	months := []string{}
+	for _, month := range t.Months {
+		months = append(months, month.Start.Format("2006-01"))
+	}
+
+	rows := []trendRow{}
+	for _, name := range t.Deployments() {
+		rows = append(rows, trendRow{Deployment: name, Counts: t.Counts[name], Deltas: Deltas(t.Counts[name])})
+	}
+
+	totals := t.Totals()
+	return json.Marshal(struct {
+		Months      []string   `json:"months"`
+		Deployments []trendRow `json:"deployments"`
+		Totals      []int      `json:"totals"`
+		Deltas      []int      `json:"deltas"`
+	}{months, rows, totals, Deltas(totals)})
+}
@@ -0,0 +1,81 @@
+package deployments_test
+
+import (
+	"encoding/json"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/pivotal-cloudops/bosh-stats/deployments"
+)
+
+var _ = Describe("Deploy trend", func() {
+	events := `
+	[
+		{"id": "6", "timestamp": 1449000000, "user": "admin", "action": "update", "object_type": "deployment", "deployment": "cf", "context": {"before": {}, "after": {}}},
+		{"id": "5", "timestamp": 1448990000, "user": "admin", "action": "update", "object_type": "deployment", "deployment": "cf", "context": {"before": {}, "after": {}}},
+		{"id": "4", "timestamp": 1447434000, "user": "admin", "action": "update", "object_type": "deployment", "deployment": "redis", "context": {"before": {}, "after": {}}},
+		{"id": "3", "timestamp": 1447434000, "user": "repave", "action": "update", "object_type": "deployment", "deployment": "cf", "context": {"before": {}, "after": {}}},
+		{"id": "2", "timestamp": 1444000000, "user": "admin", "action": "update", "object_type": "deployment", "deployment": "cf", "context": {"before": {}, "after": {}}},
+		{"id": "1", "timestamp": 1444000000, "user": "admin", "action": "update", "error": "failed", "object_type": "deployment", "deployment": "cf"}
+	]`
+
+	fake := serveDirector(serveEvents(events))
+
+	It("counts successful deploys per month from a single walk of the events", func() {
+		trend, err := fake.deployCounter.DeployTrend("2015/10..2015/12", 999, "repave", "")
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(trend.Months).To(HaveLen(3))
+		Expect(trend.Months[0].Label()).To(Equal("Oct 2015"))
+		Expect(trend.Months[2].Label()).To(Equal("Dec 2015"))
+
+		Expect(trend.Deployments()).To(Equal([]string{"cf", "redis"}))
+		Expect(trend.Counts["cf"]).To(Equal([]int{1, 0, 2}))
+		Expect(trend.Counts["redis"]).To(Equal([]int{0, 1, 0}))
+		Expect(trend.Totals()).To(Equal([]int{1, 1, 2}))
+		Expect(fake.director.ReceivedRequests()).To(HaveLen(1))
+	})
+
+	It("marshals months, counts and month-over-month deltas", func() {
+		trend, err := fake.deployCounter.DeployTrend("2015/10..2015/12", 999, "repave", "")
+		Expect(err).NotTo(HaveOccurred())
+
+		jsonOutput, err := json.Marshal(trend)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(jsonOutput).To(MatchJSON(`{
+			"months": ["2015-10", "2015-11", "2015-12"],
+			"deployments": [
+				{"deployment": "cf", "counts": [1, 0, 2], "deltas": [-1, 2]},
+				{"deployment": "redis", "counts": [0, 1, 0], "deltas": [1, -1]}
+			],
+			"totals": [1, 1, 2],
+			"deltas": [0, 1]
+		}`))
+	})
+})
+
+var _ = Describe("NewTrend", func() {
+	It("clips the first and last month to the period", func() {
+		period := deployments.Period{
+			Start: time.Date(2017, time.January, 15, 0, 0, 0, 0, time.UTC),
+			End:   time.Date(2017, time.March, 10, 0, 0, 0, 0, time.UTC),
+		}
+
+		trend := deployments.NewTrend(period)
+		Expect(trend.Months).To(HaveLen(3))
+		Expect(trend.Months[0].Start).To(Equal(period.Start))
+		Expect(trend.Months[1].Label()).To(Equal("Feb 2017"))
+		Expect(trend.Months[2].End).To(Equal(period.End))
+	})
+
+	It("ignores deploys outside the period", func() {
+		trend := deployments.NewTrend(deployments.Period{
+			Start: time.Date(2017, time.January, 1, 0, 0, 0, 0, time.UTC),
+			End:   time.Date(2017, time.January, 31, 23, 59, 59, 0, time.UTC),
+		})
+
+		trend.Add("cf", time.Date(2017, time.February, 1, 0, 0, 0, 0, time.UTC))
+		Expect(trend.Counts).To(BeEmpty())
+	})
+})
@@ -0,0 +1,82 @@
+package deployments
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+)
+
+const HumanUserClass = "human"
+
+type UserClass struct {
+	Name    string   `json:"name"`
+	Users   []string `json:"users"`
+	matcher UserMatcher
+}
+
+// LoadUserClasses reads a JSON list of user classes. Users are names or
+// /regex/ patterns, as for the repave user; a user in several classes
+// belongs to the first.
+func LoadUserClasses(path string) ([]UserClass, error) {
+	contents, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var classes []UserClass
+	err = json.Unmarshal(contents, &classes)
+	if err != nil {
+		return nil, fmt.Errorf("parsing user classes %s: %s", path, err)
+	}
+
+	seen := make(map[string]bool)
+	for i, class := range classes {
+		if class.Name == "" || class.Name == HumanUserClass || seen[class.Name] {
+			return nil, fmt.Errorf("parsing user classes %s: class names must be unique, non-empty and not %q", path, HumanUserClass)
+		}
+		seen[class.Name] = true
+
+		classes[i].matcher, err = newUserMatcher(class.Users)
+		if err != nil {
+			return nil, fmt.Errorf("parsing user classes %s: %s", path, err)
+		}
+	}
+
+	return classes, nil
+}
+
+func UserClassNames(classes []UserClass) []string {
+	names := []string{}
+	for _, class := range classes {
+		names = append(names, class.Name)
+	}
+	return append(names, HumanUserClass)
+}
+
+func ClassifyUser(classes []UserClass, user string) string {
+	for _, class := range classes {
+		if class.matcher.Matches(user) {
+			return class.Name
+		}
+	}
+	return HumanUserClass
+}
+
+// CountByUserClass turns deploy counts by deployment and user, as counted by
+// SuccessfulDeploysByUser, into counts by deployment and user class.
+func CountByUserClass(countByDeploymentAndUser map[string]map[string]int, classes []UserClass) map[string]map[string]int {
+	countByClass := make(map[string]map[string]int)
+
+	for deployment, countByUser := range countByDeploymentAndUser {
+		countByClass[deployment] = make(map[string]int)
+		for _, className := range UserClassNames(classes) {
+			countByClass[deployment][className] = 0
+		}
+
+		for user, count := range countByUser {
+			countByClass[deployment][ClassifyUser(classes, user)] += count
+		}
+	}
+
+	return countByClass
+}
@@ -0,0 +1,75 @@
+package deployments_test
+
+import (
+	"io/ioutil"
+	"os"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/pivotal-cloudops/bosh-stats/deployments"
+)
+
+var _ = Describe("User classes", func() {
+	var classesFile string
+
+	writeClasses := func(contents string) {
+		file, err := ioutil.TempFile("", "user-classes")
+		Expect(err).NotTo(HaveOccurred())
+		defer file.Close()
+
+		_, err = file.WriteString(contents)
+		Expect(err).NotTo(HaveOccurred())
+		classesFile = file.Name()
+	}
+
+	AfterEach(func() {
+		os.Remove(classesFile)
+	})
+
+	It("classifies users by the first class they match, everyone else as human", func() {
+		writeClasses(`[
+			{"name": "repave", "users": ["repave", "/^repave-/"]},
+			{"name": "ci", "users": ["/^ci-/", "/-bot$/"]}
+		]`)
+
+		classes, err := deployments.LoadUserClasses(classesFile)
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(deployments.UserClassNames(classes)).To(Equal([]string{"repave", "ci", "human"}))
+		Expect(deployments.ClassifyUser(classes, "repave")).To(Equal("repave"))
+		Expect(deployments.ClassifyUser(classes, "repave-bot")).To(Equal("repave"))
+		Expect(deployments.ClassifyUser(classes, "ci-cf")).To(Equal("ci"))
+		Expect(deployments.ClassifyUser(classes, "admin")).To(Equal("human"))
+	})
+
+	It("sums counts by user into counts by class", func() {
+		writeClasses(`[{"name": "ci", "users": ["/^ci-/"]}]`)
+
+		classes, err := deployments.LoadUserClasses(classesFile)
+		Expect(err).NotTo(HaveOccurred())
+
+		countByClass := deployments.CountByUserClass(map[string]map[string]int{
+			"cf":    {"ci-cf": 3, "ci-diego": 1, "admin": 2},
+			"diego": {"admin": 1},
+		}, classes)
+
+		Expect(countByClass).To(Equal(map[string]map[string]int{
+			"cf":    {"ci": 4, "human": 2},
+			"diego": {"ci": 0, "human": 1},
+		}))
+	})
+
+	It("rejects duplicate class names", func() {
+		writeClasses(`[{"name": "ci", "users": ["a"]}, {"name": "ci", "users": ["b"]}]`)
+
+		_, err := deployments.LoadUserClasses(classesFile)
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("rejects invalid patterns", func() {
+		writeClasses(`[{"name": "ci", "users": ["/ci-(/"]}]`)
+
+		_, err := deployments.LoadUserClasses(classesFile)
+		Expect(err).To(MatchError(ContainSubstring("invalid user pattern")))
+	})
+})
@@ -0,0 +1,116 @@
+package deployments
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"sync"
+)
+
+// UserMatcher matches event users against a comma separated list of user
+// names, where an entry between slashes such as /^ci-/ is a regular
+// expression. Commas inside an expression do not separate entries.
+type UserMatcher struct {
+	names    []string
+	patterns []*regexp.Regexp
+}
+
+var userMatchers = struct {
+	sync.Mutex
+	bySpec map[string]UserMatcher
+}{bySpec: make(map[string]UserMatcher)}
+
+// ParseUserMatcher parses a list of users. An empty list matches events
+// without a user, like the single user name it replaces.
+func ParseUserMatcher(spec string) (UserMatcher, error) {
+	if spec == "" {
+		return UserMatcher{names: []string{""}}, nil
+	}
+	return newUserMatcher(splitUserSpec(spec))
+}
+
+// splitUserSpec splits spec at the commas outside /.../ entries.
+func splitUserSpec(spec string) []string {
+	entries := []string{}
+	start := 0
+	inPattern := false
+
+	for i := 0; i < len(spec); i++ {
+		switch spec[i] {
+		case '\\':
+			if inPattern {
+				i++
+			}
+		case '/':
+			if inPattern {
+				inPattern = false
+			} else if strings.TrimSpace(spec[start:i]) == "" {
+				inPattern = true
+			}
+		case ',':
+			if !inPattern {
+				entries = append(entries, spec[start:i])
+				start = i + 1
+			}
+		}
+	}
+
+	return append(entries, spec[start:])
+}
+
+func newUserMatcher(entries []string) (UserMatcher, error) {
+	matcher := UserMatcher{}
+
+	for _, entry := range entries {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		if len(entry) > 1 && strings.HasPrefix(entry, "/") && strings.HasSuffix(entry, "/") {
+			pattern, err := regexp.Compile(entry[1 : len(entry)-1])
+			if err != nil {
+				return UserMatcher{}, fmt.Errorf("invalid user pattern %q: %s", entry, err)
+			}
+			matcher.patterns = append(matcher.patterns, pattern)
+		} else {
+			matcher.names = append(matcher.names, entry)
+		}
+	}
+
+	return matcher, nil
+}
+
+func (m UserMatcher) Matches(user string) bool {
+	for _, name := range m.names {
+		if user == name {
+			return true
+		}
+	}
+
+	for _, pattern := range m.patterns {
+		if pattern.MatchString(user) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// cachedUserMatcher parses spec once, since it is checked against every
+// event.
+func cachedUserMatcher(spec string) (UserMatcher, error) {
+	userMatchers.Lock()
+	defer userMatchers.Unlock()
+
+	if matcher, ok := userMatchers.bySpec[spec]; ok {
+		return matcher, nil
+	}
+
+	matcher, err := ParseUserMatcher(spec)
+	if err != nil {
+		return UserMatcher{}, err
+	}
+	userMatchers.bySpec[spec] = matcher
+	return matcher, nil
+}
@@ -0,0 +1,60 @@
+package deployments_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/pivotal-cloudops/bosh-stats/deployments"
+)
+
+var _ = Describe("UserMatcher", func() {
+	It("matches user names exactly", func() {
+		matcher, err := deployments.ParseUserMatcher("repave, upgrader")
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(matcher.Matches("repave")).To(BeTrue())
+		Expect(matcher.Matches("upgrader")).To(BeTrue())
+		Expect(matcher.Matches("repave-bot")).To(BeFalse())
+	})
+
+	It("matches users against patterns between slashes", func() {
+		matcher, err := deployments.ParseUserMatcher("/^ci-.*/,/bot$/")
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(matcher.Matches("ci-cf")).To(BeTrue())
+		Expect(matcher.Matches("repave-bot")).To(BeTrue())
+		Expect(matcher.Matches("admin")).To(BeFalse())
+	})
+
+	It("keeps commas inside patterns", func() {
+		matcher, err := deployments.ParseUserMatcher("repave, /^ci-[a-z]{1,3}$/")
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(matcher.Matches("repave")).To(BeTrue())
+		Expect(matcher.Matches("ci-cf")).To(BeTrue())
+		Expect(matcher.Matches("ci-diego")).To(BeFalse())
+	})
+
+	It("matches events without a user when empty", func() {
+		matcher, err := deployments.ParseUserMatcher("")
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(matcher.Matches("")).To(BeTrue())
+		Expect(matcher.Matches("admin")).To(BeFalse())
+	})
+
+	It("rejects invalid patterns", func() {
+		_, err := deployments.ParseUserMatcher("/ci-(/")
+		Expect(err).To(MatchError(ContainSubstring(`invalid user pattern "/ci-(/"`)))
+	})
+
+	Context("when counting deploys", func() {
+		fake := serveDirector()
+
+		It("returns the error of an invalid pattern before fetching events", func() {
+			runningCount := make(map[string]int)
+			err := fake.deployCounter.SuccessfulDeploys("2015/11", 999, "repave, /ci-(/", &runningCount, "")
+			Expect(err).To(MatchError(ContainSubstring(`invalid user pattern "/ci-(/"`)))
+			Expect(fake.director.ReceivedRequests()).To(BeEmpty())
+		})
+	})
+})
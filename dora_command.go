@@ -0,0 +1,99 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"github.com/pivotal-cloudops/bosh-stats/deployments"
+)
+
+func runDORA(args []string) error {
+	flags := newFlagSet("dora", "-calendarMonth YYYY/MM [options]", "Show deploy frequency, lead time, change failure rate and time to restore per deployment.")
+	connection := addConnectionFlags(flags, true)
+	periodOpts := addPeriodFlags(flags)
+	repaveUser := addRepaveUserFlag(flags)
+	deployment := flags.String("deployment", "", "The deployment to filter out")
+	outputJson := flags.Bool("json", false, "print JSON to standard out (output is a table by default)")
+	formatOpts := addFormatFlags(flags)
+	flags.Parse(args)
+
+	location, reportingPeriod := mustParsePeriod(flags, periodOpts)
+	if err := formatOpts.validate(*outputJson); err != nil {
+		exitWithUsage(flags, err)
+	}
+	if err := connection.validate(); err != nil {
+		exitWithUsage(flags, err)
+	}
+	deployCounter := connection.deployCounter(location)
+
+	report, err := deployCounter.DORAMetrics(periodOpts.spec(), itemsPerPage, *repaveUser, *deployment)
+	if err != nil {
+		return err
+	}
+
+	if formatOpts.delimited() {
+		formatOpts.print(doraTable(report))
+	} else if *outputJson {
+		printJSON(report)
+	} else {
+		printDORA(report, reportingPeriod.Label())
+	}
+	return nil
+}
+
+func printDORA(report deployments.DORAReport, periodLabel string) {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 1, ' ', tabwriter.AlignRight|tabwriter.Debug)
+
+	fmt.Fprintln(w, "Deployment", "\t", "Deploys/day", "\t", "Lead time", "\t", "Change failure rate", "\t", "Time to restore")
+	fmt.Fprintln(w, "--------------------", "\t", "-----------", "\t", "----------", "\t", "-------------------", "\t", "---------------")
+
+	deploymentNames := sortedKeys(report.ByDeployment)
+
+	for _, deployment := range deploymentNames {
+		printDORARow(w, deployment, report.ByDeployment[deployment])
+	}
+
+	fmt.Println()
+	fmt.Fprintln(w, "--------------------", "\t", "-----------", "\t", "----------", "\t", "-------------------", "\t", "---------------")
+	printDORARow(w, periodLabel, report.Overall)
+	w.Flush()
+}
+
+func printDORARow(w *tabwriter.Writer, name string, metrics deployments.DORAMetrics) {
+	leadTime := "-"
+	if metrics.LeadTimeSamples > 0 {
+		leadTime = formatDuration(metrics.LeadTime)
+	}
+
+	timeToRestore := "-"
+	if metrics.Restores > 0 {
+		timeToRestore = formatDuration(metrics.TimeToRestore)
+	}
+
+	fmt.Fprintln(w, name, "\t", fmt.Sprintf("%.2f", metrics.DeploysPerDay), "\t", leadTime, "\t", formatRatio(metrics.ChangeFailureRate), "\t", timeToRestore)
+}
+
+func doraTable(report deployments.DORAReport) *table {
+	t := newTable("deployment", "deploys", "deploys_per_day", "lead_time_seconds", "change_failure_rate", "time_to_restore_seconds")
+
+	deploymentNames := sortedKeys(report.ByDeployment)
+
+	for _, deployment := range deploymentNames {
+		t.addRow(doraCells(deployment, report.ByDeployment[deployment])...)
+	}
+
+	t.addTotals(doraCells("total", report.Overall)...)
+	return t
+}
+
+func doraCells(name string, metrics deployments.DORAMetrics) []interface{} {
+	var leadTime, timeToRestore interface{} = "", ""
+	if metrics.LeadTimeSamples > 0 {
+		leadTime = metrics.LeadTime
+	}
+	if metrics.Restores > 0 {
+		timeToRestore = metrics.TimeToRestore
+	}
+	return []interface{}{name, metrics.Deploys, metrics.DeploysPerDay, leadTime, metrics.ChangeFailureRate, timeToRestore}
+}
@@ -0,0 +1,73 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"github.com/pivotal-cloudops/bosh-stats/deployments"
+)
+
+func runDrift(args []string) error {
+	flags := newFlagSet("drift", "[options]", "Show the version of each release every deployment runs now, and how many deployed versions it is behind the newest.")
+	connection := addConnectionFlags(flags, false)
+	releaseName := flags.String("release", "", "Only show this release")
+	outputJson := flags.Bool("json", false, "print JSON to standard out (output is a table by default)")
+	formatOpts := addFormatFlags(flags)
+	flags.Parse(args)
+
+	if err := formatOpts.validate(*outputJson); err != nil {
+		exitWithUsage(flags, err)
+	}
+	if err := connection.validate(); err != nil {
+		exitWithUsage(flags, err)
+	}
+	deployCounter := connection.deployCounter(nil)
+
+	drifts, err := deployCounter.ReleaseDrift(*releaseName)
+	if err != nil {
+		return err
+	}
+
+	if formatOpts.delimited() {
+		formatOpts.print(driftTable(drifts))
+	} else if *outputJson {
+		printJSON(drifts)
+	} else {
+		printDrift(drifts)
+	}
+	return nil
+}
+
+func printDrift(drifts []deployments.ReleaseDrift) {
+	behindDeployments := 0
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 1, ' ', tabwriter.AlignRight|tabwriter.Debug)
+
+	fmt.Fprintln(w, "Release", "\t", "Deployment", "\t", "Version", "\t", "Newest", "\t", "Behind")
+	fmt.Fprintln(w, "--------------------", "\t", "--------------------", "\t", "----------", "\t", "----------", "\t", "--------------------")
+
+	for _, drift := range drifts {
+		behind := ""
+		if drift.VersionsBehind > 0 {
+			behindDeployments += 1
+			behind = fmt.Sprintf("%d versions behind", drift.VersionsBehind)
+			if drift.VersionsBehind == 1 {
+				behind = "1 version behind"
+			}
+		}
+		fmt.Fprintln(w, drift.Release, "\t", drift.Deployment, "\t", drift.Version, "\t", drift.NewestVersion, "\t", behind)
+	}
+
+	fmt.Println()
+	fmt.Fprintln(w, "--------------------", "\t", "--------------------", "\t", "----------", "\t", "----------", "\t", "--------------------")
+	fmt.Fprintln(w, "", "\t", "", "\t", "", "\t", "", "\t", fmt.Sprintf("%d of %d behind", behindDeployments, len(drifts)))
+	w.Flush()
+}
+
+func driftTable(drifts []deployments.ReleaseDrift) *table {
+	t := newTable("release", "deployment", "version", "newest_version", "versions_behind")
+	for _, drift := range drifts {
+		t.addRow(drift.Release, drift.Deployment, drift.Version, drift.NewestVersion, drift.VersionsBehind)
+	}
+	return t
+}
@@ -0,0 +1,85 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/pivotal-cloudops/bosh-stats/deployments"
+)
+
+func runDurations(args []string) error {
+	flags := newFlagSet("durations", "-calendarMonth YYYY/MM [options]", "Show min/median/p95/max deploy duration per deployment in the reporting period.")
+	connection := addConnectionFlags(flags, true)
+	periodOpts := addPeriodFlags(flags)
+	repaveUser := addRepaveUserFlag(flags)
+	deployment := flags.String("deployment", "", "The deployment to filter out")
+	outputJson := flags.Bool("json", false, "print JSON to standard out (output is a table by default)")
+	formatOpts := addFormatFlags(flags)
+	flags.Parse(args)
+
+	location, reportingPeriod := mustParsePeriod(flags, periodOpts)
+	if err := formatOpts.validate(*outputJson); err != nil {
+		exitWithUsage(flags, err)
+	}
+	if err := connection.validate(); err != nil {
+		exitWithUsage(flags, err)
+	}
+	deployCounter := connection.deployCounter(location)
+
+	durationsByDeployment := make(map[string][]time.Duration)
+	err := deployCounter.DeployDurations(periodOpts.spec(), itemsPerPage, *repaveUser, &durationsByDeployment, *deployment)
+	if err != nil {
+		return err
+	}
+
+	stats := make(map[string]deployments.DurationStats)
+	allDurations := []time.Duration{}
+	for deploymentName, deployDurations := range durationsByDeployment {
+		stats[deploymentName] = deployments.SummarizeDurations(deployDurations)
+		allDurations = append(allDurations, deployDurations...)
+	}
+
+	if formatOpts.delimited() {
+		formatOpts.print(durationsTable(stats, deployments.SummarizeDurations(allDurations)))
+	} else if *outputJson {
+		printJSON(stats)
+	} else {
+		printDurations(stats, deployments.SummarizeDurations(allDurations), reportingPeriod.Label())
+	}
+	return nil
+}
+
+func printDurations(stats map[string]deployments.DurationStats, overall deployments.DurationStats, periodLabel string) {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 1, ' ', tabwriter.AlignRight|tabwriter.Debug)
+
+	fmt.Fprintln(w, "Deployment", "\t", "Deploys", "\t", "Min", "\t", "Median", "\t", "p95", "\t", "Max")
+	fmt.Fprintln(w, "--------------------", "\t", "-------", "\t", "----------", "\t", "----------", "\t", "----------", "\t", "----------")
+
+	deploymentNames := sortedKeys(stats)
+
+	for _, deployment := range deploymentNames {
+		s := stats[deployment]
+		fmt.Fprintln(w, deployment, "\t", s.Count, "\t", formatDuration(s.Min), "\t", formatDuration(s.Median), "\t", formatDuration(s.P95), "\t", formatDuration(s.Max))
+	}
+
+	fmt.Println()
+	fmt.Fprintln(w, "--------------------", "\t", "-------", "\t", "----------", "\t", "----------", "\t", "----------", "\t", "----------")
+	fmt.Fprintln(w, periodLabel, "\t", overall.Count, "\t", formatDuration(overall.Min), "\t", formatDuration(overall.Median), "\t", formatDuration(overall.P95), "\t", formatDuration(overall.Max))
+	w.Flush()
+}
+
+func durationsTable(stats map[string]deployments.DurationStats, overall deployments.DurationStats) *table {
+	t := newTable("deployment", "deploys", "min_seconds", "median_seconds", "p95_seconds", "max_seconds")
+
+	deploymentNames := sortedKeys(stats)
+
+	for _, deployment := range deploymentNames {
+		s := stats[deployment]
+		t.addRow(deployment, s.Count, s.Min, s.Median, s.P95, s.Max)
+	}
+
+	t.addTotals("total", overall.Count, overall.Min, overall.Median, overall.P95, overall.Max)
+	return t
+}
@@ -0,0 +1,110 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"github.com/pivotal-cloudops/bosh-stats/deployments"
+)
+
+func runErrands(args []string) error {
+	flags := newFlagSet("errands", "-calendarMonth YYYY/MM [options]", "Show errand runs per deployment and errand in the reporting period, with pass rate and durations.")
+	connection := addConnectionFlags(flags, true)
+	periodOpts := addPeriodFlags(flags)
+	deployment := flags.String("deployment", "", "The deployment to filter out")
+	outputJson := flags.Bool("json", false, "print JSON to standard out (output is a table by default)")
+	formatOpts := addFormatFlags(flags)
+	flags.Parse(args)
+
+	location, reportingPeriod := mustParsePeriod(flags, periodOpts)
+	if err := formatOpts.validate(*outputJson); err != nil {
+		exitWithUsage(flags, err)
+	}
+	if err := connection.validate(); err != nil {
+		exitWithUsage(flags, err)
+	}
+	deployCounter := connection.deployCounter(location)
+
+	stats, err := deployCounter.ErrandRuns(periodOpts.spec(), itemsPerPage, *deployment)
+	if err != nil {
+		return err
+	}
+
+	if formatOpts.delimited() {
+		formatOpts.print(errandsTable(stats))
+	} else if *outputJson {
+		printJSON(stats)
+	} else {
+		printErrands(stats, reportingPeriod.Label())
+	}
+	return nil
+}
+
+func printErrands(stats map[string]map[string]deployments.ErrandStats, periodLabel string) {
+	totalSuccessful := 0
+	totalFailed := 0
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 1, ' ', tabwriter.AlignRight|tabwriter.Debug)
+
+	fmt.Fprintln(w, "Deployment", "\t", "Errand", "\t", "Runs", "\t", "Successful", "\t", "Failed", "\t", "Pass rate", "\t", "Median", "\t", "Max")
+	fmt.Fprintln(w, "--------------------", "\t", "--------------------", "\t", "-------", "\t", "----------", "\t", "----------", "\t", "---------", "\t", "----------", "\t", "----------")
+
+	deploymentNames := sortedKeys(stats)
+
+	for _, deployment := range deploymentNames {
+		errandNames := sortedKeys(stats[deployment])
+
+		for _, errand := range errandNames {
+			s := stats[deployment][errand]
+			totalSuccessful += s.Successful
+			totalFailed += s.Failed
+
+			median, max := "-", "-"
+			if s.Durations.Count > 0 {
+				median, max = formatDuration(s.Durations.Median), formatDuration(s.Durations.Max)
+			}
+			fmt.Fprintln(w, deployment, "\t", errand, "\t", s.Runs, "\t", s.Successful, "\t", s.Failed, "\t", formatRatio(s.PassRate), "\t", median, "\t", max)
+		}
+	}
+
+	passRate := 0.0
+	if totalSuccessful+totalFailed > 0 {
+		passRate = float64(totalSuccessful) / float64(totalSuccessful+totalFailed)
+	}
+
+	fmt.Println()
+	fmt.Fprintln(w, "--------------------", "\t", "--------------------", "\t", "-------", "\t", "----------", "\t", "----------", "\t", "---------", "\t", "----------", "\t", "----------")
+	fmt.Fprintln(w, periodLabel, "\t", "", "\t", totalSuccessful+totalFailed, "\t", totalSuccessful, "\t", totalFailed, "\t", formatRatio(passRate), "\t", "", "\t", "")
+	w.Flush()
+}
+
+func errandsTable(stats map[string]map[string]deployments.ErrandStats) *table {
+	totalSuccessful := 0
+	totalFailed := 0
+	t := newTable("deployment", "errand", "runs", "successful", "failed", "pass_rate", "median_seconds", "max_seconds")
+
+	deploymentNames := sortedKeys(stats)
+
+	for _, deployment := range deploymentNames {
+		errandNames := sortedKeys(stats[deployment])
+
+		for _, errand := range errandNames {
+			s := stats[deployment][errand]
+			totalSuccessful += s.Successful
+			totalFailed += s.Failed
+
+			var median, max interface{} = "", ""
+			if s.Durations.Count > 0 {
+				median, max = s.Durations.Median, s.Durations.Max
+			}
+			t.addRow(deployment, errand, s.Runs, s.Successful, s.Failed, s.PassRate, median, max)
+		}
+	}
+
+	passRate := 0.0
+	if totalSuccessful+totalFailed > 0 {
+		passRate = float64(totalSuccessful) / float64(totalSuccessful+totalFailed)
+	}
+	t.addTotals("total", "", totalSuccessful+totalFailed, totalSuccessful, totalFailed, passRate, "", "")
+	return t
+}
@@ -0,0 +1,27 @@
+package main
+
+import (
+	"os"
+)
+
+func runEvents(args []string) error {
+	flags := newFlagSet("events", "[options] > events.jsonl", "Write the raw events to standard out as JSON lines, all events unless a reporting period is given.\nThe output can be read back with -eventsFile.")
+	connection := addConnectionFlags(flags, true)
+	periodOpts := addPeriodFlags(flags)
+	deployment := flags.String("deployment", "", "Only write events of this deployment")
+	flags.Parse(args)
+
+	location, err := periodOpts.location()
+	if err != nil {
+		exitWithUsage(flags, err)
+	}
+	if periodOpts.spec() != "" {
+		mustParsePeriod(flags, periodOpts)
+	}
+	if err := connection.validate(); err != nil {
+		exitWithUsage(flags, err)
+	}
+	deployCounter := connection.deployCounter(location)
+
+	return deployCounter.DumpEvents(periodOpts.spec(), itemsPerPage, *deployment, os.Stdout)
+}
@@ -0,0 +1,152 @@
+package exporter
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+)
+
+type DeploySource interface {
+	SuccessfulDeploysSince(lastEventID string, itemsPerPage int, repaveUser string, runningCount *map[string]map[string]int, deployment string) (string, error)
+}
+
+type Exporter struct {
+	source       DeploySource
+	itemsPerPage int
+	repaveUser   string
+	deployment   string
+	errorLog     io.Writer
+
+	mutex             sync.Mutex
+	lastEventID       string
+	successfulDeploys map[string]map[string]int
+	lastRefresh       time.Time
+	refreshErrors     int
+}
+
+func NewExporter(source DeploySource, itemsPerPage int, repaveUser string, deployment string, errorLog io.Writer) *Exporter {
+	return &Exporter{
+		source:            source,
+		itemsPerPage:      itemsPerPage,
+		repaveUser:        repaveUser,
+		deployment:        deployment,
+		errorLog:          errorLog,
+		successfulDeploys: make(map[string]map[string]int),
+	}
+}
+
+func (e *Exporter) Refresh() error {
+	e.mutex.Lock()
+	lastEventID := e.lastEventID
+	e.mutex.Unlock()
+
+	newDeploys := make(map[string]map[string]int)
+	newestEventID, err := e.source.SuccessfulDeploysSince(lastEventID, e.itemsPerPage, e.repaveUser, &newDeploys, e.deployment)
+
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+
+	if err != nil {
+		e.refreshErrors += 1
+		return err
+	}
+
+	for deployment, byUser := range newDeploys {
+		if e.successfulDeploys[deployment] == nil {
+			e.successfulDeploys[deployment] = make(map[string]int)
+		}
+		for user, count := range byUser {
+			e.successfulDeploys[deployment][user] += count
+		}
+	}
+	e.lastEventID = newestEventID
+	e.lastRefresh = time.Now()
+
+	return nil
+}
+
+func (e *Exporter) Run(interval time.Duration, stop <-chan struct{}) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if err := e.Refresh(); err != nil {
+			fmt.Fprintln(e.errorLog, "refreshing bosh events:", err)
+		}
+
+		select {
+		case <-ticker.C:
+		case <-stop:
+			return
+		}
+	}
+}
+
+func (e *Exporter) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4")
+
+	err := WriteText(w, e.Metrics())
+	if err != nil {
+		fmt.Fprintln(e.errorLog, "writing metrics:", err)
+	}
+}
+
+func (e *Exporter) Metrics() []Metric {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+
+	deploys := Metric{
+		Name:    "bosh_successful_deploys_total",
+		Help:    "Number of successful BOSH deploys.",
+		Type:    "counter",
+		Samples: deploySamples(e.successfulDeploys),
+	}
+
+	refreshErrors := Metric{
+		Name:    "bosh_stats_refresh_errors_total",
+		Help:    "Number of failed attempts to fetch events from the BOSH director.",
+		Type:    "counter",
+		Samples: []Sample{{Value: float64(e.refreshErrors)}},
+	}
+
+	lastRefresh := Metric{
+		Name: "bosh_stats_last_refresh_timestamp_seconds",
+		Help: "Unix time of the last successful fetch of events from the BOSH director.",
+		Type: "gauge",
+	}
+	if !e.lastRefresh.IsZero() {
+		lastRefresh.Samples = []Sample{{Value: float64(e.lastRefresh.Unix())}}
+	}
+
+	return []Metric{deploys, refreshErrors, lastRefresh}
+}
+
+func deploySamples(successfulDeploys map[string]map[string]int) []Sample {
+	samples := []Sample{}
+
+	deploymentNames := []string{}
+	for deployment := range successfulDeploys {
+		deploymentNames = append(deploymentNames, deployment)
+	}
+	sort.Strings(deploymentNames)
+
+	for _, deployment := range deploymentNames {
+		users := []string{}
+		for user := range successfulDeploys[deployment] {
+			users = append(users, user)
+		}
+		sort.Strings(users)
+
+		for _, user := range users {
+			samples = append(samples, Sample{
+				Labels: []Label{{"deployment", deployment}, {"user", user}},
+				Value:  float64(successfulDeploys[deployment][user]),
+			})
+		}
+	}
+
+	return samples
+}
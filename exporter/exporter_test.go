@@ -0,0 +1,202 @@
+package exporter_test
+
+import (
+	"bytes"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/pivotal-cloudops/bosh-stats/exporter"
+)
+
+type fakeDeploySource struct {
+	lastEventIDs []string
+	newDeploys   []map[string]map[string]int
+	newestIDs    []string
+	err          error
+}
+
+func (f *fakeDeploySource) SuccessfulDeploysSince(lastEventID string, itemsPerPage int, repaveUser string, runningCount *map[string]map[string]int, deployment string) (string, error) {
+	call := len(f.lastEventIDs)
+	f.lastEventIDs = append(f.lastEventIDs, lastEventID)
+
+	if f.err != nil {
+		return lastEventID, f.err
+	}
+
+	for deployment, byUser := range f.newDeploys[call] {
+		(*runningCount)[deployment] = byUser
+	}
+	return f.newestIDs[call], nil
+}
+
+var _ = Describe("Exporter", func() {
+	var (
+		source *fakeDeploySource
+		exp    *exporter.Exporter
+	)
+
+	BeforeEach(func() {
+		source = &fakeDeploySource{
+			newDeploys: []map[string]map[string]int{
+				{
+					"cf":    {"admin": 2, "ci": 1},
+					"diego": {"admin": 1},
+				},
+				{
+					"cf": {"admin": 1},
+				},
+			},
+			newestIDs: []string{"10", "12"},
+		}
+		exp = exporter.NewExporter(source, 200, "repave", "", ioutil.Discard)
+	})
+
+	scrape := func() string {
+		recorder := httptest.NewRecorder()
+		exp.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+		Expect(recorder.Code).To(Equal(http.StatusOK))
+		return recorder.Body.String()
+	}
+
+	It("exposes successful deploys by deployment and user", func() {
+		Expect(exp.Refresh()).To(Succeed())
+
+		body := scrape()
+		Expect(body).To(ContainSubstring("# TYPE bosh_successful_deploys_total counter\n"))
+		Expect(body).To(ContainSubstring(`bosh_successful_deploys_total{deployment="cf",user="admin"} 2` + "\n"))
+		Expect(body).To(ContainSubstring(`bosh_successful_deploys_total{deployment="cf",user="ci"} 1` + "\n"))
+		Expect(body).To(ContainSubstring(`bosh_successful_deploys_total{deployment="diego",user="admin"} 1` + "\n"))
+	})
+
+	It("only asks for events newer than the last one seen and accumulates counts", func() {
+		Expect(exp.Refresh()).To(Succeed())
+		Expect(exp.Refresh()).To(Succeed())
+
+		Expect(source.lastEventIDs).To(Equal([]string{"", "10"}))
+		Expect(scrape()).To(ContainSubstring(`bosh_successful_deploys_total{deployment="cf",user="admin"} 3` + "\n"))
+	})
+
+	It("counts refresh errors and keeps the previous counts", func() {
+		Expect(exp.Refresh()).To(Succeed())
+
+		source.err = errors.New("director is down")
+		Expect(exp.Refresh()).To(MatchError("director is down"))
+
+		body := scrape()
+		Expect(body).To(ContainSubstring("bosh_stats_refresh_errors_total 1\n"))
+		Expect(body).To(ContainSubstring(`bosh_successful_deploys_total{deployment="cf",user="admin"} 2` + "\n"))
+	})
+})
+
+var _ = Describe("WriteText", func() {
+	It("escapes label values", func() {
+		buffer := &bytes.Buffer{}
+		err := exporter.WriteText(buffer, []exporter.Metric{{
+			Name:    "some_metric",
+			Help:    "Some help.",
+			Type:    "gauge",
+			Samples: []exporter.Sample{{Labels: []exporter.Label{{"name", "a \"quoted\"\\name"}}, Value: 1.5}},
+		}})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(buffer.String()).To(Equal("# HELP some_metric Some help.\n# TYPE some_metric gauge\n" + `some_metric{name="a \"quoted\"\\name"} 1.5` + "\n"))
+	})
+})
+
+var _ = Describe("PeriodMetrics", func() {
+	start := time.Date(2017, time.January, 1, 0, 0, 0, 0, time.UTC)
+	end := time.Date(2017, time.February, 1, 0, 0, 0, 0, time.UTC).Add(-time.Nanosecond)
+
+	It("describes the deploys and boundaries of the period", func() {
+		buffer := &bytes.Buffer{}
+		err := exporter.WriteText(buffer, exporter.PeriodMetrics(map[string]map[string]int{"cf": {"admin": 2}}, start, end))
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(buffer.String()).To(ContainSubstring("# TYPE bosh_period_successful_deploys gauge\n"))
+		Expect(buffer.String()).To(ContainSubstring(`bosh_period_successful_deploys{deployment="cf",user="admin"} 2` + "\n"))
+		Expect(buffer.String()).To(ContainSubstring("bosh_period_start_timestamp_seconds 1.4832288e+09\n"))
+		Expect(buffer.String()).To(ContainSubstring("bosh_period_end_timestamp_seconds 1.485907199e+09\n"))
+	})
+})
+
+var _ = Describe("WriteTextFile", func() {
+	var dir string
+
+	BeforeEach(func() {
+		var err error
+		dir, err = ioutil.TempDir("", "textfile")
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	AfterEach(func() {
+		os.RemoveAll(dir)
+	})
+
+	It("replaces the file and leaves no temporary files behind", func() {
+		path := filepath.Join(dir, "bosh_deploys.prom")
+		Expect(ioutil.WriteFile(path, []byte("stale\n"), 0644)).To(Succeed())
+
+		err := exporter.WriteTextFile(path, []exporter.Metric{{
+			Name:    "some_metric",
+			Help:    "Some help.",
+			Type:    "gauge",
+			Samples: []exporter.Sample{{Value: 1}},
+		}})
+		Expect(err).NotTo(HaveOccurred())
+
+		contents, err := ioutil.ReadFile(path)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(string(contents)).To(Equal("# HELP some_metric Some help.\n# TYPE some_metric gauge\nsome_metric 1\n"))
+
+		info, err := os.Stat(path)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(info.Mode().Perm()).To(Equal(os.FileMode(0644)))
+
+		files, err := ioutil.ReadDir(dir)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(files).To(HaveLen(1))
+	})
+
+	It("returns an error when the directory does not exist", func() {
+		err := exporter.WriteTextFile(filepath.Join(dir, "missing", "bosh_deploys.prom"), nil)
+		Expect(err).To(HaveOccurred())
+	})
+})
+
+var _ = Describe("WriteLineProtocol", func() {
+	It("writes a point per sample with labels as tags at the timestamp", func() {
+		buffer := &bytes.Buffer{}
+		err := exporter.WriteLineProtocol(buffer, []exporter.Metric{{
+			Name: "bosh_period_successful_deploys",
+			Samples: []exporter.Sample{
+				{Labels: []exporter.Label{{"deployment", "cf"}, {"user", "admin"}}, Value: 2},
+				{Labels: []exporter.Label{{"deployment", "my deployment,1"}, {"user", "a=b"}}, Value: 1},
+			},
+		}, {
+			Name:    "bosh_period_start_timestamp_seconds",
+			Samples: []exporter.Sample{{Value: 1483228800}},
+		}}, time.Unix(1483228800, 0))
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(buffer.String()).To(Equal(
+			"bosh_period_successful_deploys,deployment=cf,user=admin value=2 1483228800000000000\n" +
+				`bosh_period_successful_deploys,deployment=my\ deployment\,1,user=a\=b value=1 1483228800000000000` + "\n" +
+				"bosh_period_start_timestamp_seconds value=1483228800 1483228800000000000\n"))
+	})
+
+	It("leaves out tags with empty values", func() {
+		buffer := &bytes.Buffer{}
+		err := exporter.WriteLineProtocol(buffer, []exporter.Metric{{
+			Name:    "some_metric",
+			Samples: []exporter.Sample{{Labels: []exporter.Label{{"user", ""}}, Value: 1}},
+		}}, time.Unix(0, 0))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(buffer.String()).To(Equal("some_metric value=1 0\n"))
+	})
+})
@@ -0,0 +1,42 @@
+package exporter
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	measurementEscaper = strings.NewReplacer(`,`, `\,`, ` `, `\ `)
+	tagEscaper         = strings.NewReplacer(`,`, `\,`, `=`, `\=`, ` `, `\ `)
+)
+
+// WriteLineProtocol writes metrics in InfluxDB line protocol, one point per
+// sample with the labels as tags and the sample in the field "value", all at
+// timestamp.
+func WriteLineProtocol(w io.Writer, metrics []Metric, timestamp time.Time) error {
+	for _, metric := range metrics {
+		for _, sample := range metric.Samples {
+			_, err := fmt.Fprintf(w, "%s%s value=%s %d\n", measurementEscaper.Replace(metric.Name), formatTags(sample.Labels), strconv.FormatFloat(sample.Value, 'f', -1, 64), timestamp.UnixNano())
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+func formatTags(labels []Label) string {
+	tags := ""
+	for _, label := range labels {
+		// InfluxDB rejects tags with empty values.
+		if label.Value == "" {
+			continue
+		}
+		tags += "," + tagEscaper.Replace(label.Name) + "=" + tagEscaper.Replace(label.Value)
+	}
+	return tags
+}
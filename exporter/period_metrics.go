@@ -0,0 +1,28 @@
+package exporter
+
+import "time"
+
+// PeriodMetrics describes the successful deploys of one reporting period,
+// for writing to a file rather than serving as running counters.
+func PeriodMetrics(successfulDeploys map[string]map[string]int, start time.Time, end time.Time) []Metric {
+	return []Metric{
+		{
+			Name:    "bosh_period_successful_deploys",
+			Help:    "Number of successful BOSH deploys in the reporting period.",
+			Type:    "gauge",
+			Samples: deploySamples(successfulDeploys),
+		},
+		{
+			Name:    "bosh_period_start_timestamp_seconds",
+			Help:    "Unix time of the start of the reporting period.",
+			Type:    "gauge",
+			Samples: []Sample{{Value: float64(start.Unix())}},
+		},
+		{
+			Name:    "bosh_period_end_timestamp_seconds",
+			Help:    "Unix time of the end of the reporting period.",
+			Type:    "gauge",
+			Samples: []Sample{{Value: float64(end.Unix())}},
+		},
+	}
+}
@@ -0,0 +1,167 @@
+package exporter
+
+import (
+	"bytes"
+	"fmt"
+	"net"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DefaultNameScheme names samples by director, then deployment and user for
+// samples that have those labels.
+const DefaultNameScheme = "{director}.{deployment}.{user}.{metric}"
+
+const (
+	// Keep StatsD packets under the usual 512 byte limit for UDP.
+	statsDPacketSize = 512
+	graphiteTimeout  = 10 * time.Second
+)
+
+var unsafeNameCharacters = regexp.MustCompile(`[^A-Za-z0-9_-]+`)
+
+// MetricNamer turns samples into dotted metric paths: Prefix followed by
+// Scheme, where {metric} is the metric name, {director} is Director and any
+// other {name} is the value of that label. Each dotted part of Scheme is
+// either literal or a placeholder; placeholders without a value are left out.
+type MetricNamer struct {
+	Prefix   string
+	Scheme   string
+	Director string
+}
+
+type Sink interface {
+	Send(metrics []Metric, timestamp time.Time) error
+}
+
+type StatsDSink struct {
+	address string
+	namer   MetricNamer
+}
+
+type GraphiteSink struct {
+	address string
+	namer   MetricNamer
+}
+
+func NewStatsDSink(address string, namer MetricNamer) *StatsDSink {
+	return &StatsDSink{address: address, namer: namer}
+}
+
+func NewGraphiteSink(address string, namer MetricNamer) *GraphiteSink {
+	return &GraphiteSink{address: address, namer: namer}
+}
+
+// Send sends every sample as a StatsD gauge. StatsD stamps gauges with the
+// time it receives them, so timestamp is not used.
+func (s *StatsDSink) Send(metrics []Metric, timestamp time.Time) error {
+	conn, err := net.Dial("udp", s.address)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	names, values := s.namer.namedValues(metrics)
+
+	var packet bytes.Buffer
+	for _, name := range names {
+		line := fmt.Sprintf("%s:%s|g\n", name, strconv.FormatFloat(values[name], 'f', -1, 64))
+
+		if packet.Len() > 0 && packet.Len()+len(line) > statsDPacketSize {
+			if _, err := conn.Write(packet.Bytes()); err != nil {
+				return err
+			}
+			packet.Reset()
+		}
+		packet.WriteString(line)
+	}
+
+	if packet.Len() > 0 {
+		_, err = conn.Write(packet.Bytes())
+	}
+	return err
+}
+
+// Send writes every sample to Graphite's plaintext protocol at timestamp.
+func (g *GraphiteSink) Send(metrics []Metric, timestamp time.Time) error {
+	conn, err := net.DialTimeout("tcp", g.address, graphiteTimeout)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	conn.SetWriteDeadline(time.Now().Add(graphiteTimeout))
+
+	names, values := g.namer.namedValues(metrics)
+
+	var lines bytes.Buffer
+	for _, name := range names {
+		fmt.Fprintf(&lines, "%s %s %d\n", name, strconv.FormatFloat(values[name], 'f', -1, 64), timestamp.Unix())
+	}
+
+	_, err = conn.Write(lines.Bytes())
+	return err
+}
+
+// ValidateNameScheme rejects schemes without a {metric} part, which would
+// give every metric of a deployment and user the same name.
+func ValidateNameScheme(scheme string) error {
+	for _, segment := range strings.Split(scheme, ".") {
+		if segment == "{metric}" {
+			return nil
+		}
+	}
+	return fmt.Errorf("name scheme %q has no {metric} part", scheme)
+}
+
+func (n MetricNamer) Name(metric Metric, sample Sample) string {
+	scheme := n.Scheme
+	if scheme == "" {
+		scheme = DefaultNameScheme
+	}
+
+	values := map[string]string{"metric": metric.Name, "director": n.Director}
+	for _, label := range sample.Labels {
+		values[label.Name] = label.Value
+	}
+
+	segments := []string{}
+	if n.Prefix != "" {
+		segments = append(segments, strings.Trim(n.Prefix, "."))
+	}
+
+	for _, segment := range strings.Split(scheme, ".") {
+		if strings.HasPrefix(segment, "{") && strings.HasSuffix(segment, "}") {
+			segment = sanitizeNameSegment(values[segment[1:len(segment)-1]])
+		}
+		if segment != "" {
+			segments = append(segments, segment)
+		}
+	}
+
+	return strings.Join(segments, ".")
+}
+
+// namedValues sums the samples that get the same name, such as the deploys
+// of every user when the scheme leaves out {user}, since StatsD and Graphite
+// keep only the last value sent for a name. Names are in the order first seen.
+func (n MetricNamer) namedValues(metrics []Metric) ([]string, map[string]float64) {
+	names := []string{}
+	values := make(map[string]float64)
+
+	for _, metric := range metrics {
+		for _, sample := range metric.Samples {
+			name := n.Name(metric, sample)
+			if _, ok := values[name]; !ok {
+				names = append(names, name)
+			}
+			values[name] += sample.Value
+		}
+	}
+	return names, values
+}
+
+func sanitizeNameSegment(value string) string {
+	return strings.Trim(unsafeNameCharacters.ReplaceAllString(value, "_"), "_")
+}
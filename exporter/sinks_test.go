@@ -0,0 +1,183 @@
+package exporter_test
+
+import (
+	"bufio"
+	"io/ioutil"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/pivotal-cloudops/bosh-stats/exporter"
+)
+
+var _ = Describe("Sinks", func() {
+	metrics := []exporter.Metric{{
+		Name: "bosh_period_successful_deploys",
+		Samples: []exporter.Sample{
+			{Labels: []exporter.Label{{"deployment", "cf"}, {"user", "admin"}}, Value: 2},
+			{Labels: []exporter.Label{{"deployment", "p-redis.1"}, {"user", "ci bot"}}, Value: 1},
+		},
+	}, {
+		Name:    "bosh_period_start_timestamp_seconds",
+		Samples: []exporter.Sample{{Value: 1483228800}},
+	}}
+	namer := exporter.MetricNamer{Prefix: "ops.bosh", Director: "10.0.0.6:25555"}
+
+	Describe("MetricNamer", func() {
+		It("fills in the scheme, leaving out placeholders without values", func() {
+			Expect(namer.Name(metrics[0], metrics[0].Samples[0])).To(Equal("ops.bosh.10_0_0_6_25555.cf.admin.bosh_period_successful_deploys"))
+			Expect(namer.Name(metrics[0], metrics[0].Samples[1])).To(Equal("ops.bosh.10_0_0_6_25555.p-redis_1.ci_bot.bosh_period_successful_deploys"))
+			Expect(namer.Name(metrics[1], metrics[1].Samples[0])).To(Equal("ops.bosh.10_0_0_6_25555.bosh_period_start_timestamp_seconds"))
+		})
+
+		It("takes literal parts in a custom scheme", func() {
+			custom := exporter.MetricNamer{Scheme: "deploys.{deployment}.{metric}", Director: "ignored"}
+			Expect(custom.Name(metrics[0], metrics[0].Samples[0])).To(Equal("deploys.cf.bosh_period_successful_deploys"))
+		})
+
+		It("rejects schemes without a {metric} part", func() {
+			Expect(exporter.ValidateNameScheme(exporter.DefaultNameScheme)).To(Succeed())
+			Expect(exporter.ValidateNameScheme("{director}.{deployment}")).NotTo(Succeed())
+			Expect(exporter.ValidateNameScheme("{director}.{metric}_total")).NotTo(Succeed())
+		})
+	})
+
+	Describe("StatsDSink", func() {
+		It("sends every sample as a gauge", func() {
+			listener, err := net.ListenPacket("udp", "127.0.0.1:0")
+			Expect(err).NotTo(HaveOccurred())
+			defer listener.Close()
+
+			sink := exporter.NewStatsDSink(listener.LocalAddr().String(), namer)
+			Expect(sink.Send(metrics, time.Unix(1483228800, 0))).To(Succeed())
+
+			listener.SetReadDeadline(time.Now().Add(5 * time.Second))
+			packet := make([]byte, 1024)
+			n, _, err := listener.ReadFrom(packet)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(packet[:n])).To(Equal(
+				"ops.bosh.10_0_0_6_25555.cf.admin.bosh_period_successful_deploys:2|g\n" +
+					"ops.bosh.10_0_0_6_25555.p-redis_1.ci_bot.bosh_period_successful_deploys:1|g\n" +
+					"ops.bosh.10_0_0_6_25555.bosh_period_start_timestamp_seconds:1483228800|g\n"))
+		})
+
+		It("sums the samples a scheme gives the same name", func() {
+			listener, err := net.ListenPacket("udp", "127.0.0.1:0")
+			Expect(err).NotTo(HaveOccurred())
+			defer listener.Close()
+
+			byUser := []exporter.Metric{{
+				Name: "bosh_period_successful_deploys",
+				Samples: []exporter.Sample{
+					{Labels: []exporter.Label{{"deployment", "cf"}, {"user", "admin"}}, Value: 2},
+					{Labels: []exporter.Label{{"deployment", "cf"}, {"user", "ci"}}, Value: 3},
+				},
+			}}
+
+			sink := exporter.NewStatsDSink(listener.LocalAddr().String(), exporter.MetricNamer{Scheme: "deploys.{deployment}.{metric}"})
+			Expect(sink.Send(byUser, time.Now())).To(Succeed())
+
+			listener.SetReadDeadline(time.Now().Add(5 * time.Second))
+			packet := make([]byte, 1024)
+			n, _, err := listener.ReadFrom(packet)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(packet[:n])).To(Equal("deploys.cf.bosh_period_successful_deploys:5|g\n"))
+		})
+
+		It("splits large batches into several packets", func() {
+			listener, err := net.ListenPacket("udp", "127.0.0.1:0")
+			Expect(err).NotTo(HaveOccurred())
+			defer listener.Close()
+
+			many := exporter.Metric{Name: strings.Repeat("m", 100)}
+			for i := 0; i < 10; i++ {
+				many.Samples = append(many.Samples, exporter.Sample{Labels: []exporter.Label{{"deployment", strconv.Itoa(i)}}, Value: float64(i)})
+			}
+
+			sink := exporter.NewStatsDSink(listener.LocalAddr().String(), exporter.MetricNamer{})
+			Expect(sink.Send([]exporter.Metric{many}, time.Now())).To(Succeed())
+
+			lines := 0
+			for lines < 10 {
+				listener.SetReadDeadline(time.Now().Add(5 * time.Second))
+				packet := make([]byte, 1024)
+				n, _, err := listener.ReadFrom(packet)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(n).To(BeNumerically("<=", 512))
+				lines += strings.Count(string(packet[:n]), "\n")
+			}
+			Expect(lines).To(Equal(10))
+		})
+	})
+
+	Describe("GraphiteSink", func() {
+		It("writes every sample in the plaintext protocol at the timestamp", func() {
+			listener, err := net.Listen("tcp", "127.0.0.1:0")
+			Expect(err).NotTo(HaveOccurred())
+			defer listener.Close()
+
+			received := make(chan string, 1)
+			go func() {
+				defer GinkgoRecover()
+				conn, err := listener.Accept()
+				Expect(err).NotTo(HaveOccurred())
+				defer conn.Close()
+
+				body, err := ioutil.ReadAll(bufio.NewReader(conn))
+				Expect(err).NotTo(HaveOccurred())
+				received <- string(body)
+			}()
+
+			sink := exporter.NewGraphiteSink(listener.Addr().String(), namer)
+			Expect(sink.Send(metrics, time.Unix(1483228800, 0))).To(Succeed())
+
+			Eventually(received, 5*time.Second).Should(Receive(Equal(
+				"ops.bosh.10_0_0_6_25555.cf.admin.bosh_period_successful_deploys 2 1483228800\n" +
+					"ops.bosh.10_0_0_6_25555.p-redis_1.ci_bot.bosh_period_successful_deploys 1 1483228800\n" +
+					"ops.bosh.10_0_0_6_25555.bosh_period_start_timestamp_seconds 1483228800 1483228800\n")))
+		})
+
+		It("sums the samples a scheme gives the same name", func() {
+			listener, err := net.Listen("tcp", "127.0.0.1:0")
+			Expect(err).NotTo(HaveOccurred())
+			defer listener.Close()
+
+			received := make(chan string, 1)
+			go func() {
+				defer GinkgoRecover()
+				conn, err := listener.Accept()
+				Expect(err).NotTo(HaveOccurred())
+				defer conn.Close()
+
+				body, err := ioutil.ReadAll(conn)
+				Expect(err).NotTo(HaveOccurred())
+				received <- string(body)
+			}()
+
+			byUser := []exporter.Metric{{
+				Name: "bosh_period_successful_deploys",
+				Samples: []exporter.Sample{
+					{Labels: []exporter.Label{{"deployment", "cf"}, {"user", "admin"}}, Value: 2},
+					{Labels: []exporter.Label{{"deployment", "cf"}, {"user", "ci"}}, Value: 3},
+				},
+			}}
+
+			sink := exporter.NewGraphiteSink(listener.Addr().String(), exporter.MetricNamer{Scheme: "deploys.{deployment}.{metric}"})
+			Expect(sink.Send(byUser, time.Unix(1483228800, 0))).To(Succeed())
+
+			Eventually(received, 5*time.Second).Should(Receive(Equal("deploys.cf.bosh_period_successful_deploys 5 1483228800\n")))
+		})
+
+		It("returns an error when nothing is listening", func() {
+			listener, err := net.Listen("tcp", "127.0.0.1:0")
+			Expect(err).NotTo(HaveOccurred())
+			address := listener.Addr().String()
+			listener.Close()
+
+			Expect(exporter.NewGraphiteSink(address, namer).Send(metrics, time.Now())).NotTo(Succeed())
+		})
+	})
+})
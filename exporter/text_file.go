@@ -0,0 +1,48 @@
+package exporter
+
+import (
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// WriteTextFile writes metrics for the node exporter's textfile collector.
+// The file is replaced atomically, so the collector never reads half of it.
+func WriteTextFile(path string, metrics []Metric) error {
+	return writeFileAtomically(path, func(w io.Writer) error {
+		return WriteText(w, metrics)
+	})
+}
+
+func WriteLineProtocolFile(path string, metrics []Metric, timestamp time.Time) error {
+	return writeFileAtomically(path, func(w io.Writer) error {
+		return WriteLineProtocol(w, metrics, timestamp)
+	})
+}
+
+func writeFileAtomically(path string, write func(io.Writer) error) error {
+	// The temporary file has to be on the same filesystem for the rename
+	// to be atomic, and hidden so collectors skip it.
+	file, err := ioutil.TempFile(filepath.Dir(path), "."+filepath.Base(path)+".")
+	if err != nil {
+		return err
+	}
+
+	err = write(file)
+	if err == nil {
+		err = file.Chmod(0644)
+	}
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(file.Name(), path)
+	}
+
+	if err != nil {
+		os.Remove(file.Name())
+	}
+	return err
+}
@@ -0,0 +1,58 @@
+package exporter
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+type Label struct {
+	Name  string
+	Value string
+}
+
+type Sample struct {
+	Labels []Label
+	Value  float64
+}
+
+type Metric struct {
+	Name    string
+	Help    string
+	Type    string
+	Samples []Sample
+}
+
+var labelValueEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+func WriteText(w io.Writer, metrics []Metric) error {
+	for _, metric := range metrics {
+		_, err := fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s %s\n", metric.Name, metric.Help, metric.Name, metric.Type)
+		if err != nil {
+			return err
+		}
+
+		for _, sample := range metric.Samples {
+			_, err := fmt.Fprintf(w, "%s%s %s\n", metric.Name, formatLabels(sample.Labels), strconv.FormatFloat(sample.Value, 'g', -1, 64))
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+func formatLabels(labels []Label) string {
+	if len(labels) == 0 {
+		return ""
+	}
+
+	pairs := []string{}
+	for _, label := range labels {
+		pairs = append(pairs, fmt.Sprintf(`%s="%s"`, label.Name, labelValueEscaper.Replace(label.Value)))
+	}
+
+	return "{" + strings.Join(pairs, ",") + "}"
+}
@@ -0,0 +1,212 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/pivotal-cloudops/bosh-stats/deployments"
+)
+
+const itemsPerPage = 200
+
+func newFlagSet(name string, usage string, description string) *flag.FlagSet {
+	flags := flag.NewFlagSet(name, flag.ExitOnError)
+	flags.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: bosh-stats %s %s\n\n", name, usage)
+		fmt.Fprintln(os.Stderr, description)
+		fmt.Fprintln(os.Stderr)
+		fmt.Fprintln(os.Stderr, "Options:")
+		flags.PrintDefaults()
+	}
+	return flags
+}
+
+func exitWithUsage(flags *flag.FlagSet, err error) {
+	fmt.Fprintln(os.Stderr, err)
+	fmt.Fprintln(os.Stderr)
+	flags.Usage()
+	os.Exit(2)
+}
+
+type connectionFlags struct {
+	directorURL     *string
+	uaaURL          *string
+	uaaClientID     *string
+	uaaClientSecret *string
+	caCert          *string
+	cacheDir        *string
+	eventsFile      *string
+}
+
+func addConnectionFlags(flags *flag.FlagSet, offline bool) *connectionFlags {
+	c := &connectionFlags{
+		directorURL:     flags.String("directorUrl", "", "bosh director URL"),
+		uaaURL:          flags.String("uaaUrl", "", "UAA URL"),
+		uaaClientID:     flags.String("uaaClientId", "", "UAA Client ID"),
+		uaaClientSecret: flags.String("uaaClientSecret", "", "UAA Client Secret"),
+		caCert:          flags.String("caCert", "", "CA Cert"),
+		cacheDir:        flags.String("cacheDir", "", "Directory to keep fetched events in, so later runs only fetch new events"),
+		eventsFile:      new(string),
+	}
+	if offline {
+		flags.StringVar(c.eventsFile, "eventsFile", "", "Read events from this file instead of the director, as written by 'bosh-stats events' or by 'bosh events --json'")
+	}
+	return c
+}
+
+func (c *connectionFlags) validate() error {
+	if *c.eventsFile != "" {
+		return nil
+	}
+
+	missing := []string{}
+	for _, required := range []struct {
+		name  string
+		value string
+	}{
+		{"-directorUrl", *c.directorURL},
+		{"-uaaUrl", *c.uaaURL},
+		{"-uaaClientId", *c.uaaClientID},
+		{"-uaaClientSecret", *c.uaaClientSecret},
+	} {
+		if required.value == "" {
+			missing = append(missing, required.name)
+		}
+	}
+
+	if len(missing) > 0 {
+		return fmt.Errorf("missing required flags: %s", strings.Join(missing, ", "))
+	}
+	return nil
+}
+
+func (c *connectionFlags) deployCounter(location *time.Location) deployments.DeployCounter {
+	return deployments.DeployCounter{
+		DirectorURL:     *c.directorURL,
+		UaaURL:          *c.uaaURL,
+		UaaClientID:     *c.uaaClientID,
+		UaaClientSecret: *c.uaaClientSecret,
+		CaCert:          *c.caCert,
+		CacheDir:        *c.cacheDir,
+		EventsFile:      *c.eventsFile,
+		Timezone:        location,
+	}
+}
+
+type periodFlags struct {
+	calendarMonth *string
+	period        *string
+	from          *string
+	to            *string
+	timezone      *string
+}
+
+func addPeriodFlags(flags *flag.FlagSet) *periodFlags {
+	return &periodFlags{
+		calendarMonth: flags.String("calendarMonth", "", "Calendar month/year YYYY/MM"),
+		period:        flags.String("period", "", "Reporting period: YYYY/MM, YYYY-Www, YYYY-Qn or 'last <n>d'"),
+		from:          flags.String("from", "", "Start of the reporting period, YYYY/MM, YYYY-MM-DD or RFC3339 timestamp"),
+		to:            flags.String("to", "", "End of the reporting period, YYYY/MM, YYYY-MM-DD or RFC3339 timestamp (default now)"),
+		timezone:      flags.String("timezone", "UTC", "Timezone for reporting period boundaries, e.g. America/New_York"),
+	}
+}
+
+func (p *periodFlags) spec() string {
+	if *p.from != "" || *p.to != "" {
+		return *p.from + ".." + *p.to
+	}
+	if *p.period != "" {
+		return *p.period
+	}
+	return *p.calendarMonth
+}
+
+func (p *periodFlags) location() (*time.Location, error) {
+	return time.LoadLocation(*p.timezone)
+}
+
+func (p *periodFlags) reportingPeriod(location *time.Location) (deployments.Period, error) {
+	if p.spec() == "" {
+		return deployments.Period{}, fmt.Errorf("a reporting period is required: -calendarMonth, -period or -from/-to")
+	}
+	return deployments.ParsePeriod(p.spec(), location, time.Now())
+}
+
+func mustParsePeriod(flags *flag.FlagSet, p *periodFlags) (*time.Location, deployments.Period) {
+	location, err := p.location()
+	if err != nil {
+		exitWithUsage(flags, err)
+	}
+
+	reportingPeriod, err := p.reportingPeriod(location)
+	if err != nil {
+		exitWithUsage(flags, err)
+	}
+	return location, reportingPeriod
+}
+
+type userListFlag string
+
+func (u *userListFlag) String() string {
+	return string(*u)
+}
+
+func (u *userListFlag) Set(value string) error {
+	_, err := deployments.ParseUserMatcher(value)
+	if err != nil {
+		return err
+	}
+
+	*u = userListFlag(value)
+	return nil
+}
+
+func addRepaveUserFlag(flags *flag.FlagSet) *string {
+	users := new(string)
+	flags.Var((*userListFlag)(users), "repaveUser", "The `users` to filter out as 'repave' users: comma separated names, /regex/ entries match patterns")
+	return users
+}
+
+type formatFlags struct {
+	format   *string
+	noTotals *bool
+}
+
+func addFormatFlags(flags *flag.FlagSet) *formatFlags {
+	return &formatFlags{
+		format:   flags.String("format", "", "print `csv|tsv` with a header row to standard out, for spreadsheets (output is a table by default)"),
+		noTotals: flags.Bool("noTotals", false, "leave the totals rows out of -format output"),
+	}
+}
+
+func (f *formatFlags) validate(outputJson bool) error {
+	switch *f.format {
+	case "", "csv", "tsv":
+	default:
+		return fmt.Errorf("invalid -format %q: expected csv or tsv", *f.format)
+	}
+
+	if outputJson && *f.format != "" {
+		return fmt.Errorf("-json and -format cannot be combined")
+	}
+	return nil
+}
+
+func (f *formatFlags) delimited() bool {
+	return *f.format != ""
+}
+
+func (f *formatFlags) print(t *table) {
+	comma := ','
+	if *f.format == "tsv" {
+		comma = '\t'
+	}
+
+	if err := t.write(os.Stdout, comma, !*f.noTotals); err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+}
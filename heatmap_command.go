@@ -0,0 +1,112 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/pivotal-cloudops/bosh-stats/deployments"
+)
+
+var heatmapShades = []string{"░", "▒", "▓", "█"}
+
+func runHeatmap(args []string) error {
+	flags := newFlagSet("heatmap", "-calendarMonth YYYY/MM [options]", "Show successful deploys by weekday and hour of day, in -timezone.")
+	connection := addConnectionFlags(flags, true)
+	periodOpts := addPeriodFlags(flags)
+	repaveUser := addRepaveUserFlag(flags)
+	deployment := flags.String("deployment", "", "The deployment to filter out")
+	outputJson := flags.Bool("json", false, "print JSON to standard out (output is a heatmap by default)")
+	formatOpts := addFormatFlags(flags)
+	flags.Parse(args)
+
+	location, reportingPeriod := mustParsePeriod(flags, periodOpts)
+	if err := formatOpts.validate(*outputJson); err != nil {
+		exitWithUsage(flags, err)
+	}
+	if err := connection.validate(); err != nil {
+		exitWithUsage(flags, err)
+	}
+	deployCounter := connection.deployCounter(location)
+
+	heatmap, err := deployCounter.DeployHeatmap(periodOpts.spec(), itemsPerPage, *repaveUser, *deployment)
+	if err != nil {
+		return err
+	}
+
+	if formatOpts.delimited() {
+		formatOpts.print(heatmapTable(heatmap))
+	} else if *outputJson {
+		printJSON(heatmap)
+	} else {
+		printHeatmap(heatmap, reportingPeriod.Label())
+	}
+	return nil
+}
+
+func printHeatmap(heatmap deployments.Heatmap, periodLabel string) {
+	max := heatmap.Max()
+	total := 0
+
+	fmt.Printf("Deploys by weekday and hour, %s (%s)\n\n", periodLabel, heatmap.Location)
+
+	fmt.Printf("%-10s", "")
+	for hour := 0; hour < 24; hour++ {
+		fmt.Printf("%3d", hour)
+	}
+	fmt.Printf("  %s\n", "Total")
+
+	for _, weekday := range deployments.HeatmapWeekdays {
+		dayTotal := 0
+		fmt.Printf("%-10s", weekday)
+		for hour := 0; hour < 24; hour++ {
+			count := heatmap.Count(weekday, hour)
+			dayTotal += count
+			fmt.Printf(" %s", heatmapCell(count, max))
+		}
+		total += dayTotal
+		fmt.Printf("  %d\n", dayTotal)
+	}
+
+	fmt.Println()
+	fmt.Printf("%d total deploys, %s marks the busiest hours with %d deploys\n", total, heatmapShades[len(heatmapShades)-1], max)
+}
+
+func heatmapCell(count int, max int) string {
+	if count == 0 {
+		return " ·"
+	}
+
+	shade := (count*len(heatmapShades) - 1) / max
+	return strings.Repeat(heatmapShades[shade], 2)
+}
+
+func heatmapTable(heatmap deployments.Heatmap) *table {
+	totalByHour := make([]int, 24)
+	total := 0
+
+	header := []string{"weekday"}
+	for hour := 0; hour < 24; hour++ {
+		header = append(header, fmt.Sprintf("%02d", hour))
+	}
+	t := newTable(append(header, "total")...)
+
+	for _, weekday := range deployments.HeatmapWeekdays {
+		dayTotal := 0
+		row := []interface{}{weekday}
+		for hour := 0; hour < 24; hour++ {
+			count := heatmap.Count(weekday, hour)
+			dayTotal += count
+			totalByHour[hour] += count
+			row = append(row, count)
+		}
+		total += dayTotal
+		t.addRow(append(row, dayTotal)...)
+	}
+
+	totals := []interface{}{"total"}
+	for _, count := range totalByHour {
+		totals = append(totals, count)
+	}
+	t.addTotals(append(totals, total)...)
+	return t
+}
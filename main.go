@@ -1,105 +1,82 @@
 package main
 
 import (
-	"encoding/json"
-	"flag"
 	"fmt"
 	"os"
-	"text/tabwriter"
-	"time"
-
-	"github.com/pivotal-cloudops/bosh-stats/deployments"
 )
 
-func printHeader(w *tabwriter.Writer) {
-	fmt.Fprintln(w, "Deployment", "\t", "Count")
-	fmt.Fprintln(w, "--------------------", "\t", "--------------------")
+type command struct {
+	name    string
+	summary string
+	run     func(args []string) error
 }
 
-func friendlyCalendarMonth(calendarMonth *string) string {
-	parsedMonth, err := time.Parse("2006/01", *calendarMonth)
-	friendlyCalendarMonth := parsedMonth.Format("Jan 2006")
-
-	if err != nil {
-		friendlyCalendarMonth = *calendarMonth
-	}
-
-	return friendlyCalendarMonth
+var commands = []command{
+	{"count", "Count successful deploys per deployment", runCount},
+	{"durations", "Show min/median/p95/max deploy duration per deployment", runDurations},
+	{"dora", "Show deploy frequency, lead time, change failure rate and time to restore", runDORA},
+	{"deploy-date", "Show when a release version was first deployed", runDeployDate},
+	{"rollout", "Show when a release or stemcell version reached each deployment and which are behind", runRollout},
+	{"stemcells", "List the stemcell bumps of each deployment", runStemcells},
+	{"drift", "Show which release versions deployments run now and which are behind", runDrift},
+	{"churn", "Count VM, instance and disk churn per deployment and instance group", runChurn},
+	{"errands", "Show errand runs, pass rate and durations per deployment and errand", runErrands},
+	{"repairs", "Count VMs resurrected by the health monitor and repaired by cloud check", runRepairs},
+	{"trend", "Show successful deploys per month with month-over-month deltas", runTrend},
+	{"report", "Write a self-contained HTML or Markdown report with charts", runReport},
+	{"heatmap", "Show successful deploys by weekday and hour of day", runHeatmap},
+	{"events", "Write raw events to standard out as JSON lines", runEvents},
+	{"metrics", "Write deploy counts for Prometheus or InfluxDB, or push them to StatsD or Graphite", runMetrics},
+	{"serve", "Run as a Prometheus exporter serving deploy counts on /metrics", runServe},
 }
 
-func printJSON(numberByDeployment map[string]int) {
-	jsonOutput, err := json.Marshal(numberByDeployment)
-	fmt.Println(string(jsonOutput[:]))
-
-	if err != nil {
-		fmt.Println(err)
-		os.Exit(1)
+func printUsage() {
+	fmt.Fprintln(os.Stderr, "Usage: bosh-stats <command> [options]")
+	fmt.Fprintln(os.Stderr)
+	fmt.Fprintln(os.Stderr, "Commands:")
+	for _, c := range commands {
+		fmt.Fprintf(os.Stderr, "  %-12s %s\n", c.name, c.summary)
 	}
+	fmt.Fprintln(os.Stderr)
+	fmt.Fprintln(os.Stderr, "Run 'bosh-stats <command> -h' for the options of a command.")
 }
 
-func printResults(numberByDeployment map[string]int, calendarMonth *string) {
-	totalDeploys := 0
-	w := tabwriter.NewWriter(os.Stdout, 0, 0, 1, ' ', tabwriter.AlignRight|tabwriter.Debug)
-
-	printHeader(w)
-
-	for k, v := range numberByDeployment {
-		totalDeploys += v
-		fmt.Fprintln(w, k, "\t", v, "deploys")
+func findCommand(name string) (command, bool) {
+	for _, c := range commands {
+		if c.name == name {
+			return c, true
+		}
 	}
-
-	fmt.Println()
-	fmt.Fprintln(w, "--------------------", "\t", "--------------------")
-	fmt.Fprintln(w, friendlyCalendarMonth(calendarMonth), "\t", totalDeploys, "total deploys")
-	w.Flush()
+	return command{}, false
 }
 
 func main() {
-	var outputJson bool
-	directorURL := flag.String("directorUrl", "", "bosh director URL")
-	uaaURL := flag.String("uaaUrl", "", "UAA URL")
-	uaaClientID := flag.String("uaaClientId", "", "UAA Client ID")
-	uaaClientSecret := flag.String("uaaClientSecret", "", "UAA Client Secret")
-	caCert := flag.String("caCert", "", "CA Cert")
-	calendarMonth := flag.String("calendarMonth", "", "Calendar month/year YYYY/MM")
-	repaveUser := flag.String("repaveUser", "", "The username to filter out as the 'repave' user")
-	deployment := flag.String("deployment", "", "The deployment to filter out")
-
-	releaseName := flag.String("release", "", "The release to filter for the deploy date")
-	releaseVersion := flag.String("version", "", "The version to filter for the deploy date")
-
-	flag.BoolVar(&outputJson, "json", false, "print JSON to standard out (output is a table by default)")
-	flag.Parse()
-
-	deployCounter := deployments.DeployCounter{
-		DirectorURL:     *directorURL,
-		UaaURL:          *uaaURL,
-		UaaClientID:     *uaaClientID,
-		UaaClientSecret: *uaaClientSecret,
-		CaCert:          *caCert,
+	if len(os.Args) < 2 {
+		printUsage()
+		os.Exit(2)
 	}
 
-	if *releaseName == "" {
-		numberByDeployment := make(map[string]int)
-
-		err := deployCounter.SuccessfulDeploys(*calendarMonth, 200, *repaveUser, &numberByDeployment, *deployment)
-		if err != nil {
-			fmt.Println(err)
-			os.Exit(1)
+	name := os.Args[1]
+	if name == "help" || name == "-h" || name == "-help" || name == "--help" {
+		if len(os.Args) > 2 {
+			if c, ok := findCommand(os.Args[2]); ok {
+				c.run([]string{"-h"})
+			}
 		}
+		printUsage()
+		return
+	}
 
-		if outputJson {
-			printJSON(numberByDeployment)
-		} else {
-			printResults(numberByDeployment, calendarMonth)
-		}
+	c, ok := findCommand(name)
+	if !ok {
+		fmt.Fprintf(os.Stderr, "unknown command %q\n\n", name)
+		printUsage()
+		os.Exit(2)
+	}
 
-	} else {
-		date, err := deployCounter.DeployDate(*releaseName, *releaseVersion, 200)
-		if err != nil {
-			fmt.Println(err)
-			os.Exit(1)
-		}
-		fmt.Println(date)
+	err := c.run(os.Args[2:])
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(1)
 	}
 }
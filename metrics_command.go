@@ -0,0 +1,79 @@
+package main
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+
+	"github.com/pivotal-cloudops/bosh-stats/exporter"
+)
+
+func runMetrics(args []string) error {
+	flags := newFlagSet("metrics", "-calendarMonth YYYY/MM (-textfile FILE | -influx FILE | -statsd ADDRESS | -graphite ADDRESS) [options]", "Write or push the successful deploys of the reporting period as metrics, for a cron job to feed to Prometheus,\nInfluxDB, StatsD or Graphite. Failed deploys, durations and DORA metrics are not exported.")
+	connection := addConnectionFlags(flags, true)
+	periodOpts := addPeriodFlags(flags)
+	repaveUser := addRepaveUserFlag(flags)
+	deployment := flags.String("deployment", "", "The deployment to filter out")
+	textFile := flags.String("textfile", "", "Write a Prometheus node exporter textfile collector `file`, e.g. bosh_deploys.prom")
+	influxFile := flags.String("influx", "", "Write InfluxDB line protocol to this `file`, or - for standard out, timestamped at the start of the period")
+	statsDAddress := flags.String("statsd", "", "Send the metrics as gauges to the StatsD server at this `host:port` over UDP")
+	graphiteAddress := flags.String("graphite", "", "Send the metrics to the Graphite plaintext listener at this `host:port` over TCP, timestamped at the start of the period")
+	metricPrefix := flags.String("metricPrefix", "", "Prefix for StatsD and Graphite metric names, e.g. ops.bosh")
+	nameScheme := flags.String("nameScheme", exporter.DefaultNameScheme, "Dotted `scheme` for StatsD and Graphite metric names, of {director}, {deployment}, {user}, {metric} and literal parts; {metric} is required")
+	directorName := flags.String("directorName", "", "The {director} in StatsD and Graphite metric names (default the -directorUrl host)")
+	flags.Parse(args)
+
+	location, reportingPeriod := mustParsePeriod(flags, periodOpts)
+	if err := exporter.ValidateNameScheme(*nameScheme); err != nil {
+		exitWithUsage(flags, err)
+	}
+	if *textFile == "" && *influxFile == "" && *statsDAddress == "" && *graphiteAddress == "" {
+		exitWithUsage(flags, fmt.Errorf("at least one of -textfile, -influx, -statsd and -graphite is required"))
+	}
+	if err := connection.validate(); err != nil {
+		exitWithUsage(flags, err)
+	}
+	deployCounter := connection.deployCounter(location)
+
+	countByDeploymentAndUser := make(map[string]map[string]int)
+	err := deployCounter.SuccessfulDeploysByUser(periodOpts.spec(), itemsPerPage, *repaveUser, &countByDeploymentAndUser, *deployment)
+	if err != nil {
+		return err
+	}
+	metrics := exporter.PeriodMetrics(countByDeploymentAndUser, reportingPeriod.Start, reportingPeriod.End)
+
+	if *textFile != "" {
+		if err := exporter.WriteTextFile(*textFile, metrics); err != nil {
+			return err
+		}
+	}
+
+	if *directorName == "" {
+		if directorURL, err := url.Parse(*connection.directorURL); err == nil {
+			*directorName = directorURL.Host
+		}
+	}
+	namer := exporter.MetricNamer{Prefix: *metricPrefix, Scheme: *nameScheme, Director: *directorName}
+
+	sinks := []exporter.Sink{}
+	if *statsDAddress != "" {
+		sinks = append(sinks, exporter.NewStatsDSink(*statsDAddress, namer))
+	}
+	if *graphiteAddress != "" {
+		sinks = append(sinks, exporter.NewGraphiteSink(*graphiteAddress, namer))
+	}
+	for _, sink := range sinks {
+		if err := sink.Send(metrics, reportingPeriod.Start); err != nil {
+			return err
+		}
+	}
+
+	switch *influxFile {
+	case "":
+	case "-":
+		return exporter.WriteLineProtocol(os.Stdout, metrics, reportingPeriod.Start)
+	default:
+		return exporter.WriteLineProtocolFile(*influxFile, metrics, reportingPeriod.Start)
+	}
+	return nil
+}
@@ -0,0 +1,63 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"reflect"
+	"sort"
+	"time"
+)
+
+const timestampLayout = "2006-01-02 15:04:05 MST"
+
+var sparklineBars = []rune("▁▂▃▄▅▆▇█")
+
+func formatDuration(duration time.Duration) string {
+	return (duration - duration%time.Second).String()
+}
+
+func formatRatio(ratio float64) string {
+	return fmt.Sprintf("%.1f%%", ratio*100)
+}
+
+// formatSparkline scales counts between zero and the largest count.
+func formatSparkline(counts []int) string {
+	max := 0
+	for _, count := range counts {
+		if count > max {
+			max = count
+		}
+	}
+
+	sparkline := []rune{}
+	for _, count := range counts {
+		bar := 0
+		if max > 0 {
+			bar = count * (len(sparklineBars) - 1) / max
+		}
+		sparkline = append(sparkline, sparklineBars[bar])
+	}
+	return string(sparkline)
+}
+
+// sortedKeys returns the keys of a map keyed by strings in order, whatever
+// the map holds.
+func sortedKeys(stringKeyedMap interface{}) []string {
+	keys := []string{}
+	for _, key := range reflect.ValueOf(stringKeyedMap).MapKeys() {
+		keys = append(keys, key.String())
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func printJSON(v interface{}) {
+	jsonOutput, err := json.Marshal(v)
+	fmt.Println(string(jsonOutput[:]))
+
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+}
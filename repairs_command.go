@@ -0,0 +1,104 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"github.com/pivotal-cloudops/bosh-stats/deployments"
+)
+
+func runRepairs(args []string) error {
+	flags := newFlagSet("repairs", "-calendarMonth YYYY/MM [options]", "Count VMs resurrected by the health monitor and repaired by 'bosh cloud-check' per deployment and instance group,\nwith the mean time between resurrections.")
+	connection := addConnectionFlags(flags, true)
+	periodOpts := addPeriodFlags(flags)
+	healthMonitorUser := new(string)
+	*healthMonitorUser = "hm"
+	flags.Var((*userListFlag)(healthMonitorUser), "healthMonitorUser", "The `users` the health monitor resurrects VMs as: comma separated names, /regex/ entries match patterns")
+	deployment := flags.String("deployment", "", "The deployment to filter out")
+	outputJson := flags.Bool("json", false, "print JSON to standard out (output is a table by default)")
+	formatOpts := addFormatFlags(flags)
+	flags.Parse(args)
+
+	location, reportingPeriod := mustParsePeriod(flags, periodOpts)
+	if err := formatOpts.validate(*outputJson); err != nil {
+		exitWithUsage(flags, err)
+	}
+	if err := connection.validate(); err != nil {
+		exitWithUsage(flags, err)
+	}
+	deployCounter := connection.deployCounter(location)
+
+	stats, err := deployCounter.Repairs(periodOpts.spec(), itemsPerPage, *healthMonitorUser, *deployment)
+	if err != nil {
+		return err
+	}
+
+	if formatOpts.delimited() {
+		formatOpts.print(repairsTable(stats))
+	} else if *outputJson {
+		printJSON(stats)
+	} else {
+		printRepairs(stats, reportingPeriod.Label())
+	}
+	return nil
+}
+
+func printRepairs(stats map[string]map[string]deployments.RepairStats, periodLabel string) {
+	totalResurrections := 0
+	totalCloudCheckRepairs := 0
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 1, ' ', tabwriter.AlignRight|tabwriter.Debug)
+
+	fmt.Fprintln(w, "Deployment", "\t", "Instance group", "\t", "Resurrections", "\t", "Cloud check repairs", "\t", "Mean time between resurrections")
+	fmt.Fprintln(w, "--------------------", "\t", "--------------------", "\t", "-------------", "\t", "-------------------", "\t", "-------------------------------")
+
+	deploymentNames := sortedKeys(stats)
+
+	for _, deployment := range deploymentNames {
+		instanceGroups := sortedKeys(stats[deployment])
+
+		for _, instanceGroup := range instanceGroups {
+			s := stats[deployment][instanceGroup]
+			totalResurrections += s.Resurrections
+			totalCloudCheckRepairs += s.CloudCheckRepairs
+
+			meanTimeBetween := "-"
+			if s.Resurrections > 1 {
+				meanTimeBetween = formatDuration(s.MeanTimeBetweenResurrections)
+			}
+			fmt.Fprintln(w, deployment, "\t", instanceGroup, "\t", s.Resurrections, "\t", s.CloudCheckRepairs, "\t", meanTimeBetween)
+		}
+	}
+
+	fmt.Println()
+	fmt.Fprintln(w, "--------------------", "\t", "--------------------", "\t", "-------------", "\t", "-------------------", "\t", "-------------------------------")
+	fmt.Fprintln(w, periodLabel, "\t", "", "\t", totalResurrections, "\t", totalCloudCheckRepairs, "\t", "")
+	w.Flush()
+}
+
+func repairsTable(stats map[string]map[string]deployments.RepairStats) *table {
+	totalResurrections := 0
+	totalCloudCheckRepairs := 0
+	t := newTable("deployment", "instance_group", "resurrections", "cloud_check_repairs", "mean_time_between_resurrections_seconds")
+
+	deploymentNames := sortedKeys(stats)
+
+	for _, deployment := range deploymentNames {
+		instanceGroups := sortedKeys(stats[deployment])
+
+		for _, instanceGroup := range instanceGroups {
+			s := stats[deployment][instanceGroup]
+			totalResurrections += s.Resurrections
+			totalCloudCheckRepairs += s.CloudCheckRepairs
+
+			var meanTimeBetween interface{} = ""
+			if s.Resurrections > 1 {
+				meanTimeBetween = s.MeanTimeBetweenResurrections
+			}
+			t.addRow(deployment, instanceGroup, s.Resurrections, s.CloudCheckRepairs, meanTimeBetween)
+		}
+	}
+
+	t.addTotals("total", "", totalResurrections, totalCloudCheckRepairs, "")
+	return t
+}
@@ -0,0 +1,53 @@
+package report
+
+import (
+	"bytes"
+	"fmt"
+	"html"
+)
+
+const (
+	barWidth    = 48
+	barGap      = 8
+	chartHeight = 100
+	labelHeight = 20
+)
+
+// BarChart draws values as a standalone SVG bar chart, with the value above
+// each bar and its label below.
+func BarChart(labels []string, values []int) string {
+	max := 0
+	for _, value := range values {
+		if value > max {
+			max = value
+		}
+	}
+
+	width := len(values)*(barWidth+barGap) + barGap
+	height := chartHeight + labelHeight
+
+	var svg bytes.Buffer
+	fmt.Fprintf(&svg, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" viewBox="0 0 %d %d" font-family="sans-serif" font-size="10">`, width, height, width, height)
+
+	for i, value := range values {
+		barHeight := 0
+		if max > 0 {
+			// Leave room above the tallest bar for its value.
+			barHeight = value * (chartHeight - 14) / max
+		}
+
+		x := barGap + i*(barWidth+barGap)
+		y := chartHeight - barHeight
+		label := ""
+		if i < len(labels) {
+			label = html.EscapeString(labels[i])
+		}
+
+		fmt.Fprintf(&svg, `<rect x="%d" y="%d" width="%d" height="%d" fill="#4a7ebb"/>`, x, y, barWidth, barHeight)
+		fmt.Fprintf(&svg, `<text x="%d" y="%d" text-anchor="middle">%d</text>`, x+barWidth/2, y-3, value)
+		fmt.Fprintf(&svg, `<text x="%d" y="%d" text-anchor="middle">%s</text>`, x+barWidth/2, chartHeight+14, label)
+	}
+
+	svg.WriteString(`</svg>`)
+	return svg.String()
+}
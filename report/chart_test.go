@@ -0,0 +1,29 @@
+package report_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/pivotal-cloudops/bosh-stats/report"
+)
+
+var _ = Describe("BarChart", func() {
+	It("scales the bars to the largest value", func() {
+		svg := report.BarChart([]string{"Jan", "Feb"}, []int{2, 4})
+
+		Expect(svg).To(HavePrefix(`<svg xmlns="http://www.w3.org/2000/svg" width="120" height="120"`))
+		Expect(svg).To(ContainSubstring(`<rect x="8" y="57" width="48" height="43"`))
+		Expect(svg).To(ContainSubstring(`<rect x="64" y="14" width="48" height="86"`))
+		Expect(svg).To(ContainSubstring(`>Feb</text>`))
+		Expect(svg).To(HaveSuffix("</svg>"))
+	})
+
+	It("draws empty bars when there are no deploys", func() {
+		svg := report.BarChart([]string{"Jan"}, []int{0})
+		Expect(svg).To(ContainSubstring(`height="0"`))
+	})
+
+	It("escapes labels", func() {
+		svg := report.BarChart([]string{"<a&b>"}, []int{1})
+		Expect(svg).To(ContainSubstring(">&lt;a&amp;b&gt;</text>"))
+	})
+})
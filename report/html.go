@@ -0,0 +1,58 @@
+package report
+
+import (
+	"html/template"
+	"io"
+)
+
+var htmlTemplate = template.Must(template.New("report").Funcs(template.FuncMap{
+	"chart": func(labels []string, values []int) template.HTML {
+		return template.HTML(BarChart(labels, values))
+	},
+}).Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>BOSH deploys, {{.Period}}</title>
+<style>
+body { font-family: sans-serif; margin: 2em; color: #222; }
+table { border-collapse: collapse; margin-bottom: 2em; }
+th, td { border: 1px solid #ccc; padding: 0.3em 0.8em; text-align: right; }
+th:first-child, td:first-child { text-align: left; }
+tfoot td { font-weight: bold; }
+.chart { display: inline-block; margin: 0 2em 2em 0; vertical-align: top; }
+</style>
+</head>
+<body>
+<h1>BOSH deploys, {{.Period}}</h1>
+{{if .Director}}<p>Director: {{.Director}}</p>
+{{end}}
+<h2>Deploys per deployment</h2>
+<table>
+<thead><tr><th>Deployment</th>{{range .Months}}<th>{{.}}</th>{{end}}<th>Total</th></tr></thead>
+<tbody>
+{{range .Deployments}}<tr><td>{{.Name}}</td>{{range .Counts}}<td>{{.}}</td>{{end}}<td>{{.Total}}</td></tr>
+{{end}}</tbody>
+<tfoot><tr><td>Total</td>{{range .Totals}}<td>{{.}}</td>{{end}}<td>{{.Total}}</td></tr></tfoot>
+</table>
+
+<h2>Monthly totals</h2>
+<table>
+<thead><tr><th>Month</th><th>Deploys</th></tr></thead>
+<tbody>
+{{range .MonthlyTotals}}<tr><td>{{.Month}}</td><td>{{.Deploys}}</td></tr>
+{{end}}</tbody>
+</table>
+<div class="chart">{{chart .Months .Totals}}</div>
+
+<h2>Deploys per month by deployment</h2>
+{{range .Deployments}}<div class="chart"><h3>{{.Name}}</h3>{{chart $.Months .Counts}}</div>
+{{end}}</body>
+</html>
+`))
+
+// WriteHTML writes r as a single HTML page with inline styles and charts, so
+// it can be mailed or attached without any other files.
+func WriteHTML(w io.Writer, r Report) error {
+	return htmlTemplate.Execute(w, r)
+}
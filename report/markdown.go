@@ -0,0 +1,47 @@
+package report
+
+import (
+	"encoding/base64"
+	"io"
+	"strings"
+	"text/template"
+)
+
+var markdownCellEscaper = strings.NewReplacer(`|`, `\|`, "\n", " ")
+
+var markdownTemplate = template.Must(template.New("report").Funcs(template.FuncMap{
+	"cell": markdownCellEscaper.Replace,
+	"chart": func(labels []string, values []int) string {
+		return "data:image/svg+xml;base64," + base64.StdEncoding.EncodeToString([]byte(BarChart(labels, values)))
+	},
+}).Parse(`# BOSH deploys, {{.Period}}
+{{if .Director}}
+Director: {{.Director}}
+{{end}}
+## Deploys per deployment
+
+| Deployment |{{range .Months}} {{cell .}} |{{end}} Total |
+|:---|{{range .Months}}---:|{{end}}---:|
+{{range .Deployments}}| {{cell .Name}} |{{range .Counts}} {{.}} |{{end}} {{.Total}} |
+{{end}}| **Total** |{{range .Totals}} **{{.}}** |{{end}} **{{.Total}}** |
+
+## Monthly totals
+
+| Month | Deploys |
+|:---|---:|
+{{range .MonthlyTotals}}| {{cell .Month}} | {{.Deploys}} |
+{{end}}
+![Monthly totals]({{chart .Months .Totals}})
+
+## Deploys per month by deployment
+{{range .Deployments}}
+### {{.Name}}
+
+![{{cell .Name}}]({{chart $.Months .Counts}})
+{{end}}`))
+
+// WriteMarkdown writes r as Markdown, with the charts embedded as SVG data
+// URIs rather than linked files.
+func WriteMarkdown(w io.Writer, r Report) error {
+	return markdownTemplate.Execute(w, r)
+}
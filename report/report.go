@@ -0,0 +1,71 @@
+package report
+
+import "sort"
+
+// Report holds deploy counts per deployment for each month of a reporting
+// period, in the order of Months.
+type Report struct {
+	Director    string
+	Period      string
+	Months      []string
+	Deployments []Deployment
+	Totals      []int
+}
+
+type Deployment struct {
+	Name   string
+	Counts []int
+}
+
+type MonthTotal struct {
+	Month   string
+	Deploys int
+}
+
+func New(director string, period string, months []string, countsByDeployment map[string][]int) Report {
+	report := Report{
+		Director: director,
+		Period:   period,
+		Months:   months,
+		Totals:   make([]int, len(months)),
+	}
+
+	names := []string{}
+	for name := range countsByDeployment {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		counts := countsByDeployment[name]
+		report.Deployments = append(report.Deployments, Deployment{Name: name, Counts: counts})
+		for i, count := range counts {
+			report.Totals[i] += count
+		}
+	}
+	return report
+}
+
+func (r Report) Total() int {
+	return sum(r.Totals)
+}
+
+func (r Report) MonthlyTotals() []MonthTotal {
+	monthlyTotals := []MonthTotal{}
+	for i, month := range r.Months {
+		monthlyTotals = append(monthlyTotals, MonthTotal{Month: month, Deploys: r.Totals[i]})
+	}
+	return monthlyTotals
+}
+
+func (d Deployment) Total() int {
+	return sum(d.Counts)
+}
+
+func sum(counts []int) int {
+	total := 0
+	for _, count := range counts {
+		total += count
+	}
+	return total
+}
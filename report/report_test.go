@@ -0,0 +1,79 @@
+package report_test
+
+import (
+	"bytes"
+	"encoding/base64"
+	"regexp"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/pivotal-cloudops/bosh-stats/report"
+)
+
+var _ = Describe("Report", func() {
+	var r report.Report
+
+	BeforeEach(func() {
+		r = report.New("https://director.example.com:25555", "2017-01-01 - 2017-02-28", []string{"Jan 2017", "Feb 2017"}, map[string][]int{
+			"redis": {0, 1},
+			"cf":    {3, 2},
+		})
+	})
+
+	It("sorts deployments and totals the counts", func() {
+		Expect(r.Deployments).To(HaveLen(2))
+		Expect(r.Deployments[0].Name).To(Equal("cf"))
+		Expect(r.Deployments[0].Total()).To(Equal(5))
+		Expect(r.Totals).To(Equal([]int{3, 3}))
+		Expect(r.Total()).To(Equal(6))
+		Expect(r.MonthlyTotals()).To(Equal([]report.MonthTotal{{"Jan 2017", 3}, {"Feb 2017", 3}}))
+	})
+
+	Describe("WriteHTML", func() {
+		It("writes the tables and inline charts without external assets", func() {
+			var html bytes.Buffer
+			Expect(report.WriteHTML(&html, r)).To(Succeed())
+
+			Expect(html.String()).To(ContainSubstring("<h1>BOSH deploys, 2017-01-01 - 2017-02-28</h1>"))
+			Expect(html.String()).To(ContainSubstring("<tr><td>cf</td><td>3</td><td>2</td><td>5</td></tr>"))
+			Expect(html.String()).To(ContainSubstring("<tr><td>Jan 2017</td><td>3</td></tr>"))
+			Expect(regexp.MustCompile(`<svg `).FindAllString(html.String(), -1)).To(HaveLen(3))
+			Expect(html.String()).NotTo(MatchRegexp(`(src|href)=`))
+		})
+
+		It("escapes deployment names", func() {
+			r = report.New("", "Jan 2017", []string{"Jan 2017"}, map[string][]int{"<script>": {1}})
+
+			var html bytes.Buffer
+			Expect(report.WriteHTML(&html, r)).To(Succeed())
+			Expect(html.String()).NotTo(ContainSubstring("<script>"))
+		})
+	})
+
+	Describe("WriteMarkdown", func() {
+		It("writes the tables and charts as SVG data URIs", func() {
+			var markdown bytes.Buffer
+			Expect(report.WriteMarkdown(&markdown, r)).To(Succeed())
+
+			Expect(markdown.String()).To(ContainSubstring("| Deployment | Jan 2017 | Feb 2017 | Total |\n"))
+			Expect(markdown.String()).To(ContainSubstring("| cf | 3 | 2 | 5 |\n"))
+			Expect(markdown.String()).To(ContainSubstring("| **Total** | **3** | **3** | **6** |\n"))
+			Expect(markdown.String()).To(ContainSubstring("| Feb 2017 | 3 |\n"))
+
+			charts := regexp.MustCompile(`!\[[^\]]*\]\(data:image/svg\+xml;base64,([^)]+)\)`).FindAllStringSubmatch(markdown.String(), -1)
+			Expect(charts).To(HaveLen(3))
+
+			svg, err := base64.StdEncoding.DecodeString(charts[1][1])
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(svg)).To(HavePrefix("<svg "))
+		})
+
+		It("escapes table cells", func() {
+			r = report.New("", "Jan 2017", []string{"Jan 2017"}, map[string][]int{"a|b": {1}})
+
+			var markdown bytes.Buffer
+			Expect(report.WriteMarkdown(&markdown, r)).To(Succeed())
+			Expect(markdown.String()).To(ContainSubstring(`| a\|b | 1 | 1 |`))
+		})
+	})
+})
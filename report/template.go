@@ -0,0 +1,116 @@
+package report
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"strings"
+	"text/tabwriter"
+	"text/template"
+	"time"
+)
+
+// TemplateData is what count templates are rendered against:
+//
+//	.Director     the director URL, empty when reading an events file
+//	.Period       .Label, .Start and .End of the reporting period
+//	.Deployments  each deployment's .Name, .Count and .Users, sorted by name
+//	.Users        each user's .Name and .Count over all deployments, sorted by name
+//	.Total        the number of deploys over all deployments
+//	.Counts       deploys by deployment name, as a map
+//
+// Deployment .Users are also .Name and .Count pairs, sorted by name.
+type TemplateData struct {
+	Director    string
+	Period      TemplatePeriod
+	Deployments []DeploymentCount
+	Users       []UserCount
+	Total       int
+}
+
+type TemplatePeriod struct {
+	Label string
+	Start time.Time
+	End   time.Time
+}
+
+type DeploymentCount struct {
+	Name  string
+	Count int
+	Users []UserCount
+}
+
+type UserCount struct {
+	Name  string
+	Count int
+}
+
+// Template is a text/template for TemplateData. The bundled builtin:table
+// template is aligned into columns like the other tables.
+type Template struct {
+	template *template.Template
+	align    bool
+}
+
+var bundledTemplates = map[string]string{
+	"builtin:table": `
+Deployment 	 Count
+-------------------- 	 --------------------
+{{range .Deployments}}{{.Name}} 	 {{.Count}} deploys
+{{end}}-------------------- 	 --------------------
+{{.Period.Label}} 	 {{.Total}} total deploys
+`,
+	"builtin:json": `{{json .Counts}}
+`,
+}
+
+var templateFuncs = template.FuncMap{
+	"json": func(value interface{}) (string, error) {
+		jsonOutput, err := json.Marshal(value)
+		return string(jsonOutput), err
+	},
+	"join": strings.Join,
+}
+
+func (d TemplateData) Counts() map[string]int {
+	counts := make(map[string]int)
+	for _, deployment := range d.Deployments {
+		counts[deployment.Name] = deployment.Count
+	}
+	return counts
+}
+
+// LoadTemplate returns the bundled template called name, builtin:table or
+// builtin:json, or else parses the template file at that path.
+func LoadTemplate(name string) (Template, error) {
+	if text, ok := bundledTemplates[name]; ok {
+		return Template{
+			template: template.Must(template.New(name).Funcs(templateFuncs).Parse(text)),
+			align:    name == "builtin:table",
+		}, nil
+	}
+
+	text, err := ioutil.ReadFile(name)
+	if err != nil {
+		return Template{}, fmt.Errorf("reading template: %s", err)
+	}
+
+	parsed, err := template.New(name).Funcs(templateFuncs).Parse(string(text))
+	if err != nil {
+		return Template{}, err
+	}
+	return Template{template: parsed}, nil
+}
+
+func (t Template) Execute(w io.Writer, data TemplateData) error {
+	if !t.align {
+		return t.template.Execute(w, data)
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 0, 1, ' ', tabwriter.AlignRight|tabwriter.Debug)
+	if err := t.template.Execute(tw, data); err != nil {
+		return err
+	}
+	return tw.Flush()
+}
@@ -0,0 +1,118 @@
+package report_test
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/pivotal-cloudops/bosh-stats/report"
+)
+
+var _ = Describe("Templates", func() {
+	var data report.TemplateData
+
+	BeforeEach(func() {
+		data = report.TemplateData{
+			Director: "https://director.example.com:25555",
+			Period: report.TemplatePeriod{
+				Label: "Jan 2017",
+				Start: time.Date(2017, time.January, 1, 0, 0, 0, 0, time.UTC),
+				End:   time.Date(2017, time.February, 1, 0, 0, 0, 0, time.UTC).Add(-time.Nanosecond),
+			},
+			Deployments: []report.DeploymentCount{
+				{Name: "cf", Count: 5, Users: []report.UserCount{{"admin", 2}, {"ci", 3}}},
+				{Name: "redis", Count: 1, Users: []report.UserCount{{"admin", 1}}},
+			},
+			Users: []report.UserCount{{"admin", 3}, {"ci", 3}},
+			Total: 6,
+		}
+	})
+
+	It("maps deploys by deployment name", func() {
+		Expect(data.Counts()).To(Equal(map[string]int{"cf": 5, "redis": 1}))
+	})
+
+	It("renders the bundled table template aligned into columns", func() {
+		tableTemplate, err := report.LoadTemplate("builtin:table")
+		Expect(err).NotTo(HaveOccurred())
+
+		var output bytes.Buffer
+		Expect(tableTemplate.Execute(&output, data)).To(Succeed())
+		Expect(output.String()).To(Equal(`
+           Deployment | Count
+ -------------------- | --------------------
+                   cf | 5 deploys
+                redis | 1 deploys
+ -------------------- | --------------------
+             Jan 2017 | 6 total deploys
+`))
+	})
+
+	It("renders the bundled json template as deploys by deployment", func() {
+		jsonTemplate, err := report.LoadTemplate("builtin:json")
+		Expect(err).NotTo(HaveOccurred())
+
+		var output bytes.Buffer
+		Expect(jsonTemplate.Execute(&output, data)).To(Succeed())
+		Expect(output.String()).To(MatchJSON(`{"cf": 5, "redis": 1}`))
+	})
+
+	Context("with a template file", func() {
+		var templateFile *os.File
+
+		BeforeEach(func() {
+			var err error
+			templateFile, err = ioutil.TempFile("", "count-template")
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		AfterEach(func() {
+			os.Remove(templateFile.Name())
+		})
+
+		It("renders the file against the report data", func() {
+			templateFile.WriteString(`{{.Director}} {{.Period.Start.Format "2006-01"}}{{range .Deployments}} {{.Name}}={{.Count}}{{end}}`)
+			templateFile.Close()
+
+			fileTemplate, err := report.LoadTemplate(templateFile.Name())
+			Expect(err).NotTo(HaveOccurred())
+
+			var output bytes.Buffer
+			Expect(fileTemplate.Execute(&output, data)).To(Succeed())
+			Expect(output.String()).To(Equal("https://director.example.com:25555 2017-01 cf=5 redis=1"))
+		})
+
+		It("returns parse errors", func() {
+			templateFile.WriteString(`{{range .Deployments}}`)
+			templateFile.Close()
+
+			_, err := report.LoadTemplate(templateFile.Name())
+			Expect(err).To(HaveOccurred())
+		})
+	})
+
+	It("reads template files named like the bundled templates", func() {
+		dir, err := ioutil.TempDir("", "count-template")
+		Expect(err).NotTo(HaveOccurred())
+		defer os.RemoveAll(dir)
+
+		path := filepath.Join(dir, "table")
+		Expect(ioutil.WriteFile(path, []byte(`{{.Total}}`), 0644)).To(Succeed())
+
+		fileTemplate, err := report.LoadTemplate(path)
+		Expect(err).NotTo(HaveOccurred())
+
+		var output bytes.Buffer
+		Expect(fileTemplate.Execute(&output, data)).To(Succeed())
+		Expect(output.String()).To(Equal("6"))
+	})
+
+	It("returns an error for a missing template file", func() {
+		_, err := report.LoadTemplate("/does/not/exist.tmpl")
+		Expect(err).To(MatchError(ContainSubstring("reading template")))
+	})
+})
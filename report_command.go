@@ -0,0 +1,61 @@
+package main
+
+import (
+	"io"
+	"os"
+	"strings"
+
+	"github.com/pivotal-cloudops/bosh-stats/report"
+)
+
+func runReport(args []string) error {
+	flags := newFlagSet("report", "-from YYYY/MM -to YYYY/MM [options]", "Write a self-contained HTML or Markdown report of successful deploys per deployment and month, with charts.")
+	connection := addConnectionFlags(flags, true)
+	periodOpts := addPeriodFlags(flags)
+	repaveUser := addRepaveUserFlag(flags)
+	deployment := flags.String("deployment", "", "The deployment to filter out")
+	output := flags.String("output", "", "Write the report to this `file` instead of standard out; a .md file is written as Markdown")
+	markdown := flags.Bool("markdown", false, "Write Markdown instead of HTML")
+	flags.Parse(args)
+
+	location, reportingPeriod := mustParsePeriod(flags, periodOpts)
+	if err := connection.validate(); err != nil {
+		exitWithUsage(flags, err)
+	}
+	deployCounter := connection.deployCounter(location)
+
+	trend, err := deployCounter.DeployTrend(periodOpts.spec(), itemsPerPage, *repaveUser, *deployment)
+	if err != nil {
+		return err
+	}
+
+	months := []string{}
+	for _, month := range trend.Months {
+		months = append(months, month.Start.Format("Jan 2006"))
+	}
+
+	r := report.New(*connection.directorURL, reportingPeriod.Label(), months, trend.Counts)
+
+	writeReport := report.WriteHTML
+	if *markdown || strings.HasSuffix(*output, ".md") {
+		writeReport = report.WriteMarkdown
+	}
+
+	if *output == "" {
+		return writeReport(os.Stdout, r)
+	}
+	return writeReportFile(*output, r, writeReport)
+}
+
+func writeReportFile(path string, r report.Report, writeReport func(io.Writer, report.Report) error) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+
+	if err := writeReport(file, r); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
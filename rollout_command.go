@@ -0,0 +1,100 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/pivotal-cloudops/bosh-stats/deployments"
+)
+
+func runRollout(args []string) error {
+	flags := newFlagSet("rollout", "(-release NAME | -stemcell NAME) -version VERSION [options]", "Show when a release or stemcell version reached each deployment, who deployed it and the version it replaced,\nand which deployments still run an older version.")
+	connection := addConnectionFlags(flags, true)
+	releaseName := flags.String("release", "", "The release to show the rollout of")
+	stemcellName := flags.String("stemcell", "", "The stemcell to show the rollout of, instead of a release")
+	version := flags.String("version", "", "The release or stemcell version to show the rollout of")
+	timezone := flags.String("timezone", "UTC", "Timezone to show rollout times in, e.g. America/New_York")
+	outputJson := flags.Bool("json", false, "print JSON to standard out (output is a table by default)")
+	formatOpts := addFormatFlags(flags)
+	flags.Parse(args)
+
+	if (*releaseName == "") == (*stemcellName == "") {
+		exitWithUsage(flags, fmt.Errorf("exactly one of -release and -stemcell is required"))
+	}
+	if *version == "" {
+		exitWithUsage(flags, fmt.Errorf("-version is required"))
+	}
+	location, err := time.LoadLocation(*timezone)
+	if err != nil {
+		exitWithUsage(flags, err)
+	}
+	if err := formatOpts.validate(*outputJson); err != nil {
+		exitWithUsage(flags, err)
+	}
+	if err := connection.validate(); err != nil {
+		exitWithUsage(flags, err)
+	}
+	deployCounter := connection.deployCounter(location)
+
+	var report deployments.RolloutReport
+	if *stemcellName != "" {
+		report, err = deployCounter.StemcellRollout(*stemcellName, *version, itemsPerPage)
+	} else {
+		report, err = deployCounter.ReleaseRollout(*releaseName, *version, itemsPerPage)
+	}
+	if err != nil {
+		return err
+	}
+
+	if formatOpts.delimited() {
+		formatOpts.print(rolloutTable(report, location))
+	} else if *outputJson {
+		printJSON(report)
+	} else {
+		printRollout(report, location)
+	}
+	return nil
+}
+
+func printRollout(report deployments.RolloutReport, location *time.Location) {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 1, ' ', tabwriter.AlignRight|tabwriter.Debug)
+
+	fmt.Fprintln(w, "Deployment", "\t", "Version", "\t", "Rolled out", "\t", "By", "\t", "Previous version")
+	fmt.Fprintln(w, "--------------------", "\t", "----------", "\t", "-----------------------", "\t", "----------", "\t", "----------------")
+
+	for _, rollout := range report.Rollouts {
+		previousVersion := rollout.PreviousVersion
+		if previousVersion == "" {
+			previousVersion = "-"
+		}
+		fmt.Fprintln(w, rollout.Deployment, "\t", report.Version, "\t", rollout.Timestamp.In(location).Format(timestampLayout), "\t", rollout.User, "\t", previousVersion)
+	}
+
+	for _, behind := range report.Behind {
+		fmt.Fprintln(w, behind.Deployment, "\t", behind.Version, "\t", "not rolled out", "\t", "-", "\t", "-")
+	}
+
+	fmt.Println()
+	fmt.Fprintln(w, "--------------------", "\t", "----------", "\t", "-----------------------", "\t", "----------", "\t", "----------------")
+	name := report.Release
+	if report.Stemcell != "" {
+		name = report.Stemcell
+	}
+	fmt.Fprintln(w, name, "\t", report.Version, "\t", fmt.Sprintf("%d deployments", len(report.Rollouts)), "\t", fmt.Sprintf("%d behind", len(report.Behind)), "\t", "")
+	w.Flush()
+}
+
+// rolloutTable lists deployments that are behind with an empty rollout time.
+func rolloutTable(report deployments.RolloutReport, location *time.Location) *table {
+	t := newTable("deployment", "version", "rolled_out_at", "user", "previous_version")
+
+	for _, rollout := range report.Rollouts {
+		t.addRow(rollout.Deployment, report.Version, rollout.Timestamp.In(location), rollout.User, rollout.PreviousVersion)
+	}
+	for _, behind := range report.Behind {
+		t.addRow(behind.Deployment, behind.Version, "", "", "")
+	}
+	return t
+}
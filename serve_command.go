@@ -0,0 +1,30 @@
+package main
+
+import (
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/pivotal-cloudops/bosh-stats/exporter"
+)
+
+func runServe(args []string) error {
+	flags := newFlagSet("serve", "[options]", "Keep running and serve deploy counts on /metrics for Prometheus, fetching new events every -refreshInterval.")
+	connection := addConnectionFlags(flags, false)
+	repaveUser := addRepaveUserFlag(flags)
+	deployment := flags.String("deployment", "", "The deployment to filter out")
+	listenAddress := flags.String("listenAddress", ":9190", "The address to serve /metrics on")
+	refreshInterval := flags.Duration("refreshInterval", 5*time.Minute, "How often to fetch new events")
+	flags.Parse(args)
+
+	if err := connection.validate(); err != nil {
+		exitWithUsage(flags, err)
+	}
+	deployCounter := connection.deployCounter(time.UTC)
+
+	deployExporter := exporter.NewExporter(&deployCounter, itemsPerPage, *repaveUser, *deployment, os.Stderr)
+	go deployExporter.Run(*refreshInterval, make(chan struct{}))
+
+	http.Handle("/metrics", deployExporter)
+	return http.ListenAndServe(*listenAddress, nil)
+}
@@ -0,0 +1,84 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/pivotal-cloudops/bosh-stats/deployments"
+)
+
+func runStemcells(args []string) error {
+	flags := newFlagSet("stemcells", "-calendarMonth YYYY/MM [options]", "List the stemcell bumps of each deployment in the reporting period.")
+	connection := addConnectionFlags(flags, true)
+	periodOpts := addPeriodFlags(flags)
+	repaveUser := addRepaveUserFlag(flags)
+	deployment := flags.String("deployment", "", "The deployment to filter out")
+	outputJson := flags.Bool("json", false, "print JSON to standard out (output is a table by default)")
+	formatOpts := addFormatFlags(flags)
+	flags.Parse(args)
+
+	location, reportingPeriod := mustParsePeriod(flags, periodOpts)
+	if err := formatOpts.validate(*outputJson); err != nil {
+		exitWithUsage(flags, err)
+	}
+	if err := connection.validate(); err != nil {
+		exitWithUsage(flags, err)
+	}
+	deployCounter := connection.deployCounter(location)
+
+	bumpsByDeployment := make(map[string][]deployments.StemcellBump)
+	err := deployCounter.StemcellBumps(periodOpts.spec(), itemsPerPage, *repaveUser, &bumpsByDeployment, *deployment)
+	if err != nil {
+		return err
+	}
+
+	if formatOpts.delimited() {
+		formatOpts.print(stemcellBumpsTable(bumpsByDeployment, location))
+	} else if *outputJson {
+		printJSON(bumpsByDeployment)
+	} else {
+		printStemcellBumps(bumpsByDeployment, reportingPeriod.Label(), location)
+	}
+	return nil
+}
+
+func printStemcellBumps(bumpsByDeployment map[string][]deployments.StemcellBump, periodLabel string, location *time.Location) {
+	totalBumps := 0
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 1, ' ', tabwriter.AlignRight|tabwriter.Debug)
+
+	fmt.Fprintln(w, "Deployment", "\t", "Bumped at", "\t", "Stemcell", "\t", "From", "\t", "To", "\t", "By")
+	fmt.Fprintln(w, "--------------------", "\t", "-----------------------", "\t", "--------------------", "\t", "----------", "\t", "----------", "\t", "----------")
+
+	deploymentNames := sortedKeys(bumpsByDeployment)
+
+	for _, deployment := range deploymentNames {
+		for _, bump := range bumpsByDeployment[deployment] {
+			totalBumps += 1
+			from := bump.From
+			if from == "" {
+				from = "-"
+			}
+			fmt.Fprintln(w, deployment, "\t", bump.Timestamp.In(location).Format(timestampLayout), "\t", bump.Stemcell, "\t", from, "\t", bump.To, "\t", bump.User)
+		}
+	}
+
+	fmt.Println()
+	fmt.Fprintln(w, "--------------------", "\t", "-----------------------", "\t", "--------------------", "\t", "----------", "\t", "----------", "\t", "----------")
+	fmt.Fprintln(w, periodLabel, "\t", fmt.Sprintf("%d deployments", len(bumpsByDeployment)), "\t", fmt.Sprintf("%d bumps", totalBumps), "\t", "", "\t", "", "\t", "")
+	w.Flush()
+}
+
+func stemcellBumpsTable(bumpsByDeployment map[string][]deployments.StemcellBump, location *time.Location) *table {
+	t := newTable("deployment", "bumped_at", "stemcell", "from", "to", "user")
+
+	deploymentNames := sortedKeys(bumpsByDeployment)
+
+	for _, deployment := range deploymentNames {
+		for _, bump := range bumpsByDeployment[deployment] {
+			t.addRow(deployment, bump.Timestamp.In(location), bump.Stemcell, bump.From, bump.To, bump.User)
+		}
+	}
+	return t
+}
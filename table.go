@@ -0,0 +1,64 @@
+package main
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+)
+
+// table is a report as plain cells, with a header row and any totals rows
+// kept apart so they can be left out.
+type table struct {
+	header []string
+	rows   [][]string
+	totals [][]string
+}
+
+func newTable(header ...string) *table {
+	return &table{header: header}
+}
+
+func (t *table) addRow(cells ...interface{}) {
+	t.rows = append(t.rows, tableCells(cells))
+}
+
+func (t *table) addTotals(cells ...interface{}) {
+	t.totals = append(t.totals, tableCells(cells))
+}
+
+func (t *table) write(out io.Writer, comma rune, withTotals bool) error {
+	w := csv.NewWriter(out)
+	w.Comma = comma
+
+	records := append([][]string{t.header}, t.rows...)
+	if withTotals {
+		records = append(records, t.totals...)
+	}
+	for _, record := range records {
+		if err := w.Write(record); err != nil {
+			return err
+		}
+	}
+
+	w.Flush()
+	return w.Error()
+}
+
+func tableCells(cells []interface{}) []string {
+	record := []string{}
+	for _, cell := range cells {
+		switch value := cell.(type) {
+		case float64:
+			record = append(record, strconv.FormatFloat(value, 'f', 4, 64))
+		case time.Duration:
+			record = append(record, strconv.FormatInt(int64(value/time.Second), 10))
+		case time.Time:
+			record = append(record, value.Format(time.RFC3339))
+		default:
+			record = append(record, fmt.Sprint(value))
+		}
+	}
+	return record
+}
@@ -0,0 +1,103 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"github.com/pivotal-cloudops/bosh-stats/deployments"
+)
+
+func runTrend(args []string) error {
+	flags := newFlagSet("trend", "-from YYYY/MM -to YYYY/MM [options]", "Show successful deploys per deployment for each month of the reporting period, with month-over-month deltas.")
+	connection := addConnectionFlags(flags, true)
+	periodOpts := addPeriodFlags(flags)
+	repaveUser := addRepaveUserFlag(flags)
+	deployment := flags.String("deployment", "", "The deployment to filter out")
+	outputJson := flags.Bool("json", false, "print JSON to standard out (output is a table by default)")
+	formatOpts := addFormatFlags(flags)
+	flags.Parse(args)
+
+	location, reportingPeriod := mustParsePeriod(flags, periodOpts)
+	if err := formatOpts.validate(*outputJson); err != nil {
+		exitWithUsage(flags, err)
+	}
+	if err := connection.validate(); err != nil {
+		exitWithUsage(flags, err)
+	}
+	deployCounter := connection.deployCounter(location)
+
+	trend, err := deployCounter.DeployTrend(periodOpts.spec(), itemsPerPage, *repaveUser, *deployment)
+	if err != nil {
+		return err
+	}
+
+	if formatOpts.delimited() {
+		formatOpts.print(trendTable(trend))
+	} else if *outputJson {
+		printJSON(trend)
+	} else {
+		printTrend(trend, reportingPeriod.Label())
+	}
+	return nil
+}
+
+func printTrend(trend deployments.Trend, periodLabel string) {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 1, ' ', tabwriter.AlignRight|tabwriter.Debug)
+
+	header := []interface{}{"Deployment"}
+	separator := []interface{}{"--------------------"}
+	for _, month := range trend.Months {
+		header = append(header, "\t", month.Start.Format("Jan 2006"))
+		separator = append(separator, "\t", "----------")
+	}
+	header = append(header, "\t", "Trend")
+	separator = append(separator, "\t", "----------")
+
+	fmt.Fprintln(w, header...)
+	fmt.Fprintln(w, separator...)
+
+	for _, deployment := range trend.Deployments() {
+		fmt.Fprintln(w, trendRow(deployment, trend.Counts[deployment])...)
+	}
+
+	fmt.Println()
+	fmt.Fprintln(w, separator...)
+	fmt.Fprintln(w, trendRow(periodLabel, trend.Totals())...)
+	w.Flush()
+}
+
+func trendRow(label string, counts []int) []interface{} {
+	row := []interface{}{label}
+	for i, count := range counts {
+		cell := fmt.Sprint(count)
+		if i > 0 {
+			cell = fmt.Sprintf("%d (%+d)", count, count-counts[i-1])
+		}
+		row = append(row, "\t", cell)
+	}
+	return append(row, "\t", formatSparkline(counts))
+}
+
+func trendTable(trend deployments.Trend) *table {
+	header := []string{"deployment"}
+	for _, month := range trend.Months {
+		header = append(header, month.Start.Format("2006-01"))
+	}
+	t := newTable(header...)
+
+	for _, deployment := range trend.Deployments() {
+		row := []interface{}{deployment}
+		for _, count := range trend.Counts[deployment] {
+			row = append(row, count)
+		}
+		t.addRow(row...)
+	}
+
+	totals := []interface{}{"total"}
+	for _, count := range trend.Totals() {
+		totals = append(totals, count)
+	}
+	t.addTotals(totals...)
+	return t
+}